@@ -1,7 +1,10 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/DobryySoul/test-task/config"
 	_ "github.com/DobryySoul/test-task/docs"
@@ -11,10 +14,35 @@ import (
 // @title Music info
 // @version 0.0.1
 func main() {
-	cfg, err := config.LoadConfig()
+	configPath := flag.String("config", "", "path to the YAML config, overrides CONFIG_PATH")
+	autoMigrate := flag.Bool("auto-migrate", false, "apply pending migrations before starting the server")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [migrate up | down [N] | goto VERSION | force VERSION | status]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("can't init config: %s", err)
 	}
 
-	app.Run(cfg)
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			flag.Usage()
+			os.Exit(2)
+		}
+
+		if err := app.Migrate(cfg, args[1:]); err != nil {
+			log.Fatalf("migrate: %s", err)
+		}
+
+		return
+	}
+
+	cfg.PG.AutoMigrate = cfg.PG.AutoMigrate || *autoMigrate
+
+	if err := app.Run(cfg); err != nil {
+		log.Fatalf("app: %s", err)
+	}
 }
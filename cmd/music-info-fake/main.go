@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/DobryySoul/test-task/internal/client/musicinfo/fake"
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+// Локальная заглушка внешнего API с информацией о песнях.
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	flag.Parse()
+
+	srv := fake.New()
+	srv.Add(entity.GetSongResponse{
+		Group:       "Muse",
+		SongName:    "Supermassive Black Hole",
+		ReleaseDate: "16.07.2006",
+		Text: "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n" +
+			"You caught me under false pretenses\nHow long before you let me go?\n\n" +
+			"Ooh\nYou set my soul alight\nOoh\nYou set my soul alight",
+		Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	})
+
+	log.Printf("music info fake listening on %s", *addr)
+
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		log.Fatalf("music info fake: %v", err)
+	}
+}
@@ -1,34 +1,100 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// DefaultPath is tried when neither --config nor CONFIG_PATH is set.
+const DefaultPath = "./config/config.yml"
+
 type Config struct {
-	HTTP `yaml:"http"`
-	Log  `yaml:"logger"`
-	PG   `yaml:"postgres"`
+	HTTP      `yaml:"http"`
+	Log       `yaml:"logger"`
+	PG        `yaml:"postgres"`
+	MusicInfo `yaml:"music_info"`
 }
 
 type HTTP struct {
-	Port string `env-required:"true" yaml:"port" env:"PORT"`
+	Host              string        `env-default:"localhost" yaml:"host" env:"HOST"`
+	Port              string        `env-default:"8080" yaml:"port" env:"PORT"`
+	ReadTimeout       time.Duration `env-default:"10s" yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `env-default:"5s" yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `env-default:"15s" yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `env-default:"60s" yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `env-default:"20s" yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
 }
 
 type Log struct {
-	Level string `env-required:"true" yaml:"level" env:"LOG_LEVEL"`
+	Level string `env-default:"info" yaml:"level" env:"LOG_LEVEL"`
 }
 
 type PG struct {
-	URL string `env-required:"true" yaml:"pg_url" env:"PG_URL"`
+	URL          string        `env-required:"true" yaml:"pg_url" env:"PG_URL"`
+	AutoMigrate  bool          `env-default:"false" yaml:"auto_migrate" env:"PG_AUTO_MIGRATE"`
+	QueryTimeout time.Duration `env-default:"5s" yaml:"query_timeout" env:"PG_QUERY_TIMEOUT"`
+
+	MaxConns        int32         `env-default:"25" yaml:"max_conns" env:"PG_MAX_CONNS"`
+	MinConns        int32         `env-default:"2" yaml:"min_conns" env:"PG_MIN_CONNS"`
+	ConnMaxLifetime time.Duration `env-default:"30m" yaml:"conn_max_lifetime" env:"PG_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `env-default:"5m" yaml:"conn_max_idle_time" env:"PG_CONN_MAX_IDLE_TIME"`
+
+	ConnectTimeout    time.Duration `env-default:"3s" yaml:"connect_timeout" env:"PG_CONNECT_TIMEOUT"`
+	ConnectAttempts   int           `env-default:"5" yaml:"connect_attempts" env:"PG_CONNECT_ATTEMPTS"`
+	ConnectRetryDelay time.Duration `env-default:"500ms" yaml:"connect_retry_delay" env:"PG_CONNECT_RETRY_DELAY"`
+}
+
+// checkConnect rejects startup settings that would make openDB spin: the
+// retry delay must be positive, the connect timeout may be zero to disable
+// it, as in pgx.
+func (pg PG) checkConnect() error {
+	if pg.ConnectTimeout < 0 {
+		return fmt.Errorf("postgres.connect_timeout (PG_CONNECT_TIMEOUT) must not be negative, got %s", pg.ConnectTimeout)
+	}
+
+	if pg.ConnectRetryDelay <= 0 {
+		return fmt.Errorf("postgres.connect_retry_delay (PG_CONNECT_RETRY_DELAY) must be positive, got %s", pg.ConnectRetryDelay)
+	}
+
+	return nil
 }
 
-func LoadConfig() (*Config, error) {
+type MusicInfo struct {
+	// URL is checked by app.Run only: migrations do not call the API.
+	URL        string        `yaml:"url" env:"MUSIC_INFO_URL"`
+	Timeout    time.Duration `env-default:"5s" yaml:"timeout" env:"MUSIC_INFO_TIMEOUT"`
+	Retries    int           `env-default:"2" yaml:"retries" env:"MUSIC_INFO_RETRIES"`
+	RetryDelay time.Duration `env-default:"300ms" yaml:"retry_delay" env:"MUSIC_INFO_RETRY_DELAY"`
+}
+
+// LoadConfig reads the YAML file at path, falling back to CONFIG_PATH and
+// then DefaultPath, and applies environment variables on top. A file named
+// explicitly must exist; when the default file is missing the configuration
+// comes from the environment and the defaults compiled into the binary.
+func LoadConfig(path string) (*Config, error) {
 	cfg := &Config{}
 
-	err := cleanenv.ReadConfig("./config/config.yml", cfg)
-	if err != nil {
+	explicit := true
+	if path == "" {
+		path = os.Getenv("CONFIG_PATH")
+	}
+	if path == "" {
+		path, explicit = DefaultPath, false
+	}
+
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if err := cleanenv.ReadConfig(path, cfg); err != nil {
+			return nil, fmt.Errorf("can't read yml config: %w", err)
+		}
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+	default:
 		return nil, fmt.Errorf("can't read yml config: %w", err)
 	}
 
@@ -37,5 +103,9 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("can't read env: %w", err)
 	}
 
+	if err := cfg.PG.checkConnect(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("PG_URL", "postgres://localhost/musiclibrary")
+	t.Setenv("MUSIC_INFO_URL", "http://localhost:8081")
+}
+
+func TestLoadConfigWithoutMusicInfoURL(t *testing.T) {
+	t.Setenv("PG_URL", "postgres://localhost/musiclibrary")
+	t.Setenv("MUSIC_INFO_URL", "")
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("postgres: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Миграциям адрес внешнего API не нужен, его проверяет только app.Run.
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+}
+
+func TestLoadConfigConnectSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "no connect timeout", env: map[string]string{"PG_CONNECT_TIMEOUT": "0"}},
+		{name: "negative connect timeout", env: map[string]string{"PG_CONNECT_TIMEOUT": "-1s"}, wantErr: true},
+		{name: "zero retry delay", env: map[string]string{"PG_CONNECT_RETRY_DELAY": "0"}, wantErr: true},
+		{name: "negative retry delay", env: map[string]string{"PG_CONNECT_RETRY_DELAY": "-1s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte("postgres: {}\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadConfig(path); (err != nil) != tt.wantErr {
+				t.Errorf("LoadConfig error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
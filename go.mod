@@ -2,7 +2,17 @@ module github.com/DobryySoul/test-task
 
 go 1.23.0
 
-require github.com/swaggo/files v1.0.1
+require (
+	github.com/jackc/pgx/v5 v5.5.4
+	github.com/swaggo/files v1.0.1
+)
+
+require (
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/jackc/puddle/v2 v2.2.1 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+)
 
 require (
 	github.com/BurntSushi/toml v1.2.1 // indirect
@@ -1,43 +1,96 @@
 package app
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/DobryySoul/test-task/config"
+	"github.com/DobryySoul/test-task/internal/client/musicinfo"
 	"github.com/DobryySoul/test-task/internal/http/routes/handlers"
 	"github.com/DobryySoul/test-task/internal/http/routes/router"
 	"github.com/DobryySoul/test-task/internal/repo/postgres"
 	"github.com/DobryySoul/test-task/internal/service"
 	"github.com/DobryySoul/test-task/pkg/logger"
-	"github.com/go-playground/validator/v10"
 )
 
-func Run(cfg *config.Config) {
+// Run serves HTTP until SIGINT or SIGTERM, then drains in-flight requests
+// within the configured deadline before closing the database pool.
+func Run(cfg *config.Config) error {
+	if cfg.MusicInfo.URL == "" {
+		return errors.New("music info URL is required to serve, set MUSIC_INFO_URL")
+	}
+
 	log := logger.New()
 	log.Info("Initializing application")
 
-	db, err := sql.Open("postgres", cfg.PG.URL)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := openDB(ctx, cfg.PG, log)
 	if err != nil {
-		log.Fatalf("Database connection error: %v", err)
+		return err
 	}
 
-	defer func() {
-		if err := db.Close(); err != nil {
-			log.Errorf("Error closing database connection: %v", err)
-		}
-	}()
+	// Пул закрывается последним, после того как сервер дождался обработчиков.
+	defer pool.Close()
 
 	log.Info("Connected to database")
 
-	repo := postgres.NewRepository(db)
-	service := service.NewSongService(repo)
-	handler := handlers.NewHandler(service, *validator.New())
+	if cfg.PG.AutoMigrate {
+		if err := Migrate(cfg, []string{"up"}); err != nil {
+			return err
+		}
+	}
+
+	repo := postgres.NewRepository(pool, cfg.PG)
+	musicInfo := musicinfo.NewClient(cfg.MusicInfo)
+	service := service.NewSongService(repo, musicInfo)
+	handler := handlers.NewHandler(service, *handlers.NewValidator())
 	r := router.NewRouter(handler)
 
-	log.Infof("Starting server on port %s", cfg.Port)
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(cfg.HTTP.Host, cfg.HTTP.Port),
+		Handler:           r.Router,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
 
-	if err := http.ListenAndServe("localhost:"+cfg.Port, r.Router); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	go func() {
+		log.Infof("Starting server on %s", srv.Addr)
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("server failed to start: %w", err)
+	case <-ctx.Done():
+		stop()
+		log.Infof("Shutting down, waiting up to %s for in-flight requests", cfg.HTTP.ShutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+
+		return fmt.Errorf("server shutdown: %w", err)
 	}
+
+	log.Info("Server stopped")
+
+	return nil
 }
@@ -2,37 +2,106 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"log"
-	"os"
+	"strconv"
+
+	"github.com/DobryySoul/test-task/config"
+	"github.com/DobryySoul/test-task/migrations"
 
 	// migrate tools
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-func init() {
-	databaseURL := os.Getenv("PG_URL")
-	if databaseURL == "" {
-		log.Fatalf("failed to migrate database: PG_URL not found")
+const migrateUsage = "usage: migrate up | down [N] | goto VERSION | force VERSION | status"
+
+// Migrate runs a migration command against the configured database using
+// the migrations embedded into the binary. "down" without N rolls back a
+// single migration.
+func Migrate(cfg *config.Config, args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return fmt.Errorf("migration source: %w", err)
 	}
 
-	m, err := migrate.New("file://migrations", databaseURL)
+	m, err := migrate.NewWithSourceInstance("iofs", src, cfg.PG.URL)
 	if err != nil {
-		log.Fatalf("Migration initialization failed: %v", err)
+		return fmt.Errorf("migration initialization failed: %w", err)
 	}
+	defer func() {
+		srcErr, dbErr := m.Close()
+		if err == nil {
+			err = errors.Join(srcErr, dbErr)
+		}
+	}()
 
-	defer m.Close()
+	switch cmd, params := args[0], args[1:]; cmd {
+	case "up":
+		err = m.Up()
+	case "down":
+		steps := 1
+		if len(params) > 0 {
+			if steps, err = strconv.Atoi(params[0]); err != nil || steps < 1 {
+				return fmt.Errorf("down: invalid number of steps %q", params[0])
+			}
+		}
 
-	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("Migrate: up error: %s", err)
+		err = m.Steps(-steps)
+	case "goto", "force":
+		if len(params) != 1 {
+			return errors.New(migrateUsage)
+		}
+
+		version, convErr := strconv.ParseUint(params[0], 10, 32)
+		if convErr != nil {
+			return fmt.Errorf("%s: invalid version %q", cmd, params[0])
+		}
+
+		if cmd == "goto" {
+			err = m.Migrate(uint(version))
+		} else {
+			err = m.Force(int(version))
+		}
+	case "status":
+		return printStatus(m)
+	default:
+		return errors.New(migrateUsage)
 	}
 
 	if errors.Is(err, migrate.ErrNoChange) {
 		log.Println("Migrate: no change")
 
-		return
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", args[0], err)
+	}
+
+	log.Printf("Migrate: %s success", args[0])
+
+	return printStatus(m)
+}
+
+func printStatus(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		log.Println("Migrate: no migrations applied")
+
+		return nil
 	}
 
-	log.Println("Migrate: up success")
+	if err != nil {
+		return fmt.Errorf("migrate status: %w", err)
+	}
+
+	log.Printf("Migrate: version %d, dirty %t", version, dirty)
+
+	return nil
 }
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DobryySoul/test-task/config"
+	"github.com/DobryySoul/test-task/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxConnectRetryDelay caps the exponential backoff between pings.
+const maxConnectRetryDelay = 10 * time.Second
+
+// openDB configures the pool and waits until Postgres answers a ping:
+// pgxpool connects lazily. Failed pings are retried with exponential
+// backoff until ConnectAttempts is exhausted or ctx is cancelled.
+func openDB(ctx context.Context, cfg config.PG, log logger.Logger) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PG_URL: %w", err)
+	}
+
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.MaxConnLifetime = cfg.ConnMaxLifetime
+	poolCfg.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("database connection error: %w", err)
+	}
+
+	delay := cfg.ConnectRetryDelay
+	attempts := max(cfg.ConnectAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := connectContext(ctx, cfg.ConnectTimeout)
+		err = pool.Ping(pingCtx)
+		cancel()
+
+		if err == nil {
+			return pool, nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		log.Infof("Postgres is not ready (attempt %d/%d): %v; retrying in %s", attempt, attempts, err, delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			pool.Close()
+
+			return nil, fmt.Errorf("waiting for postgres: %w", ctx.Err())
+		}
+
+		delay = min(delay*2, maxConnectRetryDelay)
+	}
+
+	pool.Close()
+
+	return nil, fmt.Errorf("postgres is unreachable after %d attempts, check PG_URL and that the server is running: %w", attempts, err)
+}
+
+// connectContext bounds a ping by the connect timeout. Zero disables the
+// limit, as it does for pgx.
+func connectContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,109 @@
+package musicinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DobryySoul/test-task/config"
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+var (
+	ErrSongNotFound = errors.New("song not found in music info service")
+	ErrUnavailable  = errors.New("music info service unavailable")
+)
+
+// Client calls the external music info API, GET /info?group=&song=.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	retryDelay time.Duration
+}
+
+func NewClient(cfg config.MusicInfo) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		retries:    cfg.Retries,
+		retryDelay: cfg.RetryDelay,
+	}
+}
+
+// GetSongDetail fetches release date, text and link of the song. Network
+// errors and 5xx responses are retried with a linear backoff.
+func (c *Client) GetSongDetail(ctx context.Context, group, song string) (*entity.GetSongResponse, error) {
+	const methodName = "GetSongDetail"
+
+	query := url.Values{}
+	query.Set("group", group)
+	query.Set("song", song)
+	endpoint := c.baseURL + "/info?" + query.Encode()
+
+	var lastErr error
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%s: %w", methodName, ctx.Err())
+			case <-time.After(c.retryDelay * time.Duration(attempt)):
+			}
+		}
+
+		detail, retry, err := c.do(ctx, endpoint)
+		if err == nil {
+			return detail, nil
+		}
+
+		if !retry {
+			return nil, fmt.Errorf("%s: %w", methodName, err)
+		}
+
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("%s: %d attempts failed: %w", methodName, c.retries+1, lastErr)
+}
+
+func (c *Client) do(ctx context.Context, endpoint string) (*entity.GetSongResponse, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+
+		return nil, true, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, ErrSongNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return nil, true, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	default:
+		return nil, false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var detail entity.GetSongResponse
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		return nil, false, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &detail, false, nil
+}
@@ -0,0 +1,112 @@
+package musicinfo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DobryySoul/test-task/config"
+	"github.com/DobryySoul/test-task/internal/client/musicinfo"
+	"github.com/DobryySoul/test-task/internal/client/musicinfo/fake"
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+var supermassive = entity.GetSongResponse{
+	Group:       "Muse",
+	SongName:    "Supermassive Black Hole",
+	ReleaseDate: "16.07.2006",
+	Text:        "Ooh baby, don't you know I suffer?",
+	Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+}
+
+func newClient(t *testing.T, retries int) (*musicinfo.Client, *fake.Server) {
+	t.Helper()
+
+	srv := fake.New()
+	srv.Add(supermassive)
+
+	ts := srv.Start()
+	t.Cleanup(ts.Close)
+
+	client := musicinfo.NewClient(config.MusicInfo{
+		URL:        ts.URL,
+		Timeout:    time.Second,
+		Retries:    retries,
+		RetryDelay: time.Millisecond,
+	})
+
+	return client, srv
+}
+
+func TestGetSongDetail(t *testing.T) {
+	client, _ := newClient(t, 2)
+
+	detail, err := client.GetSongDetail(context.Background(), supermassive.Group, supermassive.SongName)
+	if err != nil {
+		t.Fatalf("GetSongDetail: %v", err)
+	}
+
+	if *detail != supermassive {
+		t.Errorf("GetSongDetail = %+v, want %+v", *detail, supermassive)
+	}
+}
+
+func TestGetSongDetailNotFound(t *testing.T) {
+	client, _ := newClient(t, 2)
+
+	_, err := client.GetSongDetail(context.Background(), "Muse", "Unknown")
+	if !errors.Is(err, musicinfo.ErrSongNotFound) {
+		t.Fatalf("GetSongDetail error = %v, want %v", err, musicinfo.ErrSongNotFound)
+	}
+}
+
+func TestGetSongDetailRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		failures int
+		wantErr  error
+	}{
+		{name: "recovers within retries", retries: 2, failures: 2},
+		{name: "no retries configured", retries: 0, failures: 1, wantErr: musicinfo.ErrUnavailable},
+		{name: "runs out of attempts", retries: 2, failures: 3, wantErr: musicinfo.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := newClient(t, tt.retries)
+			srv.FailNext(tt.failures)
+
+			detail, err := client.GetSongDetail(context.Background(), supermassive.Group, supermassive.SongName)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetSongDetail error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetSongDetail: %v", err)
+			}
+
+			if detail.SongName != supermassive.SongName {
+				t.Errorf("GetSongDetail song = %q, want %q", detail.SongName, supermassive.SongName)
+			}
+		})
+	}
+}
+
+func TestGetSongDetailCancelled(t *testing.T) {
+	client, srv := newClient(t, 5)
+	srv.FailNext(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetSongDetail(ctx, supermassive.Group, supermassive.SongName)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetSongDetail error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,85 @@
+// Package fake is an in-memory stand-in for the music info API, used for
+// local development and tests.
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+type key struct {
+	group string
+	song  string
+}
+
+type Server struct {
+	mu       sync.Mutex
+	songs    map[key]entity.GetSongResponse
+	failures int
+}
+
+func New() *Server {
+	return &Server{songs: make(map[key]entity.GetSongResponse)}
+}
+
+// Add registers a song that GET /info will return.
+func (s *Server) Add(detail entity.GetSongResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.songs[key{group: detail.Group, song: detail.SongName}] = detail
+}
+
+// FailNext makes the next n requests answer 503, to exercise client retries.
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = n
+}
+
+// Start runs the fake on a random local port; callers must Close it.
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != "/info" {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+
+	if group == "" || song == "" {
+		http.Error(w, "group and song parameters are required", http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.Lock()
+	if s.failures > 0 {
+		s.failures--
+		s.mu.Unlock()
+		http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+
+		return
+	}
+	detail, ok := s.songs[key{group: group, song: song}]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(detail)
+}
@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateLayout is the canonical date format of the API and of the database.
+const DateLayout = time.DateOnly
+
+// DateInputLayouts are the formats ParseDate accepts: ISO 8601 dates,
+// DD.MM.YYYY as returned by the music info API, and RFC 3339 timestamps,
+// whose time and zone are dropped.
+var DateInputLayouts = []string{DateLayout, "02.01.2006", time.RFC3339}
+
+var ErrInvalidDate = errors.New("invalid date")
+
+// Date is a calendar date without time or zone. It accepts any of
+// DateInputLayouts and is always written as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the date of t in t's location.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses s with the first matching layout of DateInputLayouts.
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range DateInputLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return NewDate(t), nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("%w %q: expected YYYY-MM-DD or DD.MM.YYYY", ErrInvalidDate, s)
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: must be a string", ErrInvalidDate)
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// UnmarshalParam binds query and form parameters in gin.
+func (d *Date) UnmarshalParam(param string) error {
+	return d.UnmarshalText([]byte(param))
+}
+
+// Scan reads a date column; pgx passes it as time.Time.
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+
+		return nil
+	case time.Time:
+		*d = NewDate(v)
+
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+}
+
+// Value writes the date as time.Time, the zero date as NULL.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+
+	return d.Time, nil
+}
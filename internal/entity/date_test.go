@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "2006-07-16", want: "2006-07-16"},
+		{in: "16.07.2006", want: "2006-07-16"},
+		{in: " 16.07.2006 ", want: "2006-07-16"},
+		{in: "2006-07-16T00:00:00Z", want: "2006-07-16"},
+		{in: "2006-07-16T23:30:00+03:00", want: "2006-07-16"},
+		{in: "2006-07-16T01:00:00-05:00", want: "2006-07-16"},
+		{in: "", wantErr: true},
+		{in: "2006/07/16", wantErr: true},
+		{in: "2006-13-01", wantErr: true},
+		{in: "31.02.2006", wantErr: true},
+		{in: "07.16.2006", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDate(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDate) {
+					t.Fatalf("ParseDate(%q) error = %v, want %v", tt.in, err, ErrInvalidDate)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseDate(%q): %v", tt.in, err)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("ParseDate(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	var v struct {
+		D Date `json:"d"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"d":"16.07.2006"}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if string(data) != `{"d":"2006-07-16"}` {
+		t.Errorf("Marshal = %s, want canonical date", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"d":null}`), &v); err != nil || !v.D.IsZero() {
+		t.Errorf("Unmarshal null = %v, %v; want zero date", v.D, err)
+	}
+
+	if data, _ := json.Marshal(v); string(data) != `{"d":null}` {
+		t.Errorf("Marshal zero = %s, want null", data)
+	}
+
+	for _, in := range []string{`{"d":20060716}`, `{"d":"yesterday"}`} {
+		if err := json.Unmarshal([]byte(in), &v); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("Unmarshal %s error = %v, want %v", in, err, ErrInvalidDate)
+		}
+	}
+}
+
+func TestDateText(t *testing.T) {
+	var d Date
+	if err := d.UnmarshalParam("16.07.2006"); err != nil {
+		t.Fatalf("UnmarshalParam: %v", err)
+	}
+
+	text, err := d.MarshalText()
+	if err != nil || string(text) != "2006-07-16" {
+		t.Errorf("MarshalText = %s, %v; want 2006-07-16", text, err)
+	}
+
+	var back Date
+	if err := back.UnmarshalText(text); err != nil || back != d {
+		t.Errorf("UnmarshalText(%s) = %v, %v; want %v", text, back, err, d)
+	}
+
+	if err := back.UnmarshalParam("bad"); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("UnmarshalParam error = %v, want %v", err, ErrInvalidDate)
+	}
+}
+
+func TestDateScanValue(t *testing.T) {
+	want := NewDate(time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC))
+
+	sources := []any{
+		time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC),
+		"2006-07-16",
+		[]byte("2006-07-16"),
+	}
+
+	for _, src := range sources {
+		var d Date
+		if err := d.Scan(src); err != nil || d != want {
+			t.Errorf("Scan(%#v) = %v, %v; want %v", src, d, err, want)
+		}
+	}
+
+	var d Date
+	if err := d.Scan(nil); err != nil || !d.IsZero() {
+		t.Errorf("Scan(nil) = %v, %v; want zero date", d, err)
+	}
+
+	if err := d.Scan(42); err == nil {
+		t.Error("Scan(42) succeeded, want error")
+	}
+
+	value, err := want.Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+
+	var back Date
+	if err := back.Scan(value); err != nil || back != want {
+		t.Errorf("Scan(Value()) = %v, %v; want %v", back, err, want)
+	}
+
+	if value, err := (Date{}).Value(); value != nil || err != nil {
+		t.Errorf("zero Value = %v, %v; want nil", value, err)
+	}
+}
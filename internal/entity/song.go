@@ -1,48 +1,147 @@
 package entity
 
+import "encoding/json"
+
+// Artist model info
+// @Description Информация об исполнителе
 type Artist struct {
 	ArtistID  int    `json:"artist_id"`
 	GroupName string `json:"group_name"`
 }
 
+// ArtistInput model info
+// @Description Данные для создания или изменения исполнителя
+type ArtistInput struct {
+	GroupName string `json:"group_name" validate:"required,max=255"`
+}
+
+// ArtistsResponse model info
+// @Description Ответ со списком исполнителей и пагинацией
+type ArtistsResponse struct {
+	Data       []Artist `json:"data"`
+	Page       int      `json:"page"`
+	TotalPages int      `json:"total_pages"`
+	TotalItems int      `json:"total_items"`
+}
+
 // Song model info
-// @Description Информация о песне
+// @Description Информация о песне, song_text и link равны null, если они очищены
 type Song struct {
-	ArtistID    int    `json:"artist_id"`
-	SongID      int    `json:"song_id"`
-	SongName    string `json:"song_name"`
-	ReleaseDate string `json:"release_date"`
-	SongText    string `json:"song_text"`
-	Link        string `json:"link"`
+	ArtistID    int     `json:"artist_id"`
+	Group       string  `json:"group"`
+	SongID      int     `json:"song_id"`
+	SongName    string  `json:"song_name"`
+	ReleaseDate Date    `json:"release_date" swaggertype:"string" format:"date" example:"2006-07-16"`
+	SongText    *string `json:"song_text" extensions:"x-nullable"`
+	Link        *string `json:"link" extensions:"x-nullable"`
+	Version     int     `json:"version"`
 }
 
 // CreateSongInput model info
-// @Description Информация о песне после создания
+// @Description Группа и название новой песни, остальные данные запрашиваются во внешнем API
 type CreateSongInput struct {
-	SongName    string `json:"song_name"`
-	ReleaseDate string `json:"release_date"`
+	Group    string `json:"group" validate:"required,max=255"`
+	SongName string `json:"song_name" validate:"required,max=255"`
+}
+
+// SongInput model info
+// @Description Полные данные песни для замены существующей записи
+type SongInput struct {
+	ArtistID    int    `json:"artist_id" validate:"required,min=1"`
+	SongName    string `json:"song_name" validate:"required,max=255"`
+	ReleaseDate Date   `json:"release_date" swaggertype:"string" format:"date" example:"2006-07-16" validate:"required"`
 	SongText    string `json:"song_text"`
-	Link        string `json:"link"`
-	ArtistID    int    `json:"artist_id"`
+	Link        string `json:"link" validate:"omitempty,url,max=255"`
 }
 
 // UpdateSongInput model info
-// @Description Изменение параметра существующей песни
+// @Description Частичное изменение песни в формате JSON Merge Patch (RFC 7396): отсутствующие поля не меняются, null очищает text и link
 type UpdateSongInput struct {
-	Group       string `json:"group"`
-	Song        string `json:"song"`
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	Group       NullableString `json:"group" swaggertype:"string" validate:"omitempty,max=255"`
+	Song        NullableString `json:"song" swaggertype:"string" validate:"omitempty,max=255"`
+	ReleaseDate NullableDate   `json:"releaseDate" swaggertype:"string" format:"date" example:"2006-07-16"`
+	Text        NullableString `json:"text" swaggertype:"string"`
+	Link        NullableString `json:"link" swaggertype:"string" validate:"omitempty,url,max=255"`
+}
+
+// NullableString is a merge patch member: Set reports whether the key was
+// present in the document and Null whether its value was null.
+type NullableString struct {
+	Set   bool
+	Null  bool
+	Value string
+}
+
+func (n *NullableString) UnmarshalJSON(data []byte) error {
+	n.Set = true
+
+	if string(data) == "null" {
+		n.Null = true
+
+		return nil
+	}
+
+	return json.Unmarshal(data, &n.Value)
+}
+
+// NullableDate is a merge patch member holding a date.
+type NullableDate struct {
+	Set   bool
+	Null  bool
+	Value Date
+}
+
+func (n *NullableDate) UnmarshalJSON(data []byte) error {
+	n.Set = true
+
+	if string(data) == "null" {
+		n.Null = true
+
+		return nil
+	}
+
+	return json.Unmarshal(data, &n.Value)
 }
 
 // SongsResponse model info
 // @Description Ответ со списком песен и пагинацией
 type SongsResponse struct {
 	Data       []Song `json:"data"`
-	Page       int    `json:"page"`
-	TotalPages int    `json:"total_pages"`
-	TotalItems int    `json:"total_items"`
+	Page       int    `json:"page,omitempty"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	TotalItems *int   `json:"total_items,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SongList is one page of the song list. Total is nil unless it was
+// requested; NextCursor is empty on the last page.
+type SongList struct {
+	Songs      []Song
+	Total      *int
+	NextCursor string
+}
+
+// SearchQuery model info
+// @Description Поисковый запрос в синтаксисе websearch: слова, "фразы", or, -исключения
+type SearchQuery struct {
+	Q string `form:"q" validate:"required,max=255"`
+}
+
+// SearchResult model info
+// @Description Найденная песня, ее релевантность и фрагмент текста с подсветкой совпадений. Фрагмент - HTML: текст экранирован, совпадения выделены тегом <b>
+type SearchResult struct {
+	Song
+	Rank    float64 `json:"rank" example:"0.6079271"`
+	Snippet string  `json:"snippet" example:"Ooh baby, don't you know I <b>suffer</b>?"`
+}
+
+// SearchResponse model info
+// @Description Результаты поиска по убыванию релевантности
+type SearchResponse struct {
+	Data       []SearchResult `json:"data"`
+	Page       int            `json:"page"`
+	TotalPages int            `json:"total_pages"`
+	TotalItems int            `json:"total_items"`
 }
 
 // CreateSongResponse model info
@@ -55,25 +154,126 @@ type GetSongResponse struct {
 	Link        string `json:"link"`
 }
 
+// VersesResponse model info
+// @Description Куплеты песни с пагинацией, куплеты разделяются пустыми строками
+type VersesResponse struct {
+	SongID      int      `json:"song_id"`
+	Song        string   `json:"song"`
+	Text        []string `json:"text"`
+	Page        int      `json:"page"`
+	Limit       int      `json:"limit"`
+	TotalVerses int      `json:"total_verses"`
+	TotalPages  int      `json:"total_pages"`
+}
+
+// VerseResponse model info
+// @Description Один куплет песни, index начинается с 1
+type VerseResponse struct {
+	SongID      int    `json:"song_id"`
+	Song        string `json:"song"`
+	Index       int    `json:"index"`
+	Text        string `json:"text"`
+	TotalVerses int    `json:"total_verses"`
+}
+
 // ErrorResponse model info
-// @Description Ответ об ошибке
+// @Description Ответ об ошибке, для ошибок валидации (422) содержит список полей, для 404 в /info - похожие песни
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error       string       `json:"error"`
+	Fields      []FieldError `json:"fields,omitempty"`
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+}
+
+// Suggestion model info
+// @Description Похожая песня для запроса без точного совпадения, score от 0 до 1
+type Suggestion struct {
+	SongID int     `json:"song_id" example:"1"`
+	Group  string  `json:"group" example:"Muse"`
+	Song   string  `json:"song" example:"Supermassive Black Hole"`
+	Score  float64 `json:"score" example:"0.72"`
 }
 
-// SongFilter model info
-// @Description Фильтр по параметрам
+// FieldError model info
+// @Description Ошибка валидации поля: имя поля в запросе, нарушенное правило и описание
+type FieldError struct {
+	Field   string `json:"field" example:"release_date"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"is required"`
+}
+
+// SongFilterQuery model info
+// @Description Параметры фильтрации списка песен в виде строки запроса
+type SongFilterQuery struct {
+	Group           string   `form:"group"`
+	GroupExact      bool     `form:"group_exact"`
+	Song            string   `form:"song"`
+	ReleaseDate     Date     `form:"release_date"`
+	ReleaseDateFrom Date     `form:"release_date_from"`
+	ReleaseDateTo   Date     `form:"release_date_to"`
+	Text            string   `form:"text"`
+	Link            string   `form:"link" validate:"omitempty,max=255"`
+	HasLink         *bool    `form:"has_link"`
+	ArtistID        []string `form:"artist_id"`
+	Sort            string   `form:"sort"`
+}
+
+// MatchOp selects how a name filter is compared with the column.
+type MatchOp string
+
+const (
+	MatchExact    MatchOp = "exact"
+	MatchPrefix   MatchOp = "prefix"
+	MatchContains MatchOp = "contains"
+)
+
+// NameMatch is a parsed "[op:]value" filter, e.g. "prefix:Mu".
+type NameMatch struct {
+	Op    MatchOp
+	Value string
+}
+
+// SongFilter is the parsed song listing filter; nil and empty fields do not
+// restrict the result.
 type SongFilter struct {
-	Group       *string `form:"group"`
-	Song        *string `form:"song"`
-	ReleaseDate *string `form:"release_date"`
-	Text        *string `form:"text"`
-	Link        *string `form:"link"`
+	Group           *NameMatch
+	Song            *NameMatch
+	Text            *NameMatch
+	ReleaseDate     *Date
+	ReleaseDateFrom *Date
+	ReleaseDateTo   *Date
+	Link            *string
+	HasLink         *bool
+	ArtistIDs       []int
+}
+
+// SortField is one key of the song list order, e.g. "-release_date".
+type SortField struct {
+	Name string
+	Desc bool
 }
 
 // Pagination model info
 // @Description Пагинация
 type Pagination struct {
-	Page  int `form:"page" validate:"min=1"`
-	Limit int `form:"limit" validate:"min=1,max=100"`
+	Page  int `form:"page,default=1" validate:"min=1"`
+	Limit int `form:"limit,default=10" validate:"min=1,max=100"`
+}
+
+// SongPage selects a page of the song list: by number, or after the row the
+// cursor points to when Cursor is set. Page is ignored in cursor mode.
+type SongPage struct {
+	Pagination
+	Cursor    string `form:"cursor" validate:"omitempty,max=2048"`
+	WithTotal *bool  `form:"with_total"`
+}
+
+// CountTotal reports whether the total count should be computed. It is on
+// by default for numbered pages and off for cursor pages, where counting
+// would defeat the point of keyset pagination.
+func (p SongPage) CountTotal() bool {
+	if p.WithTotal != nil {
+		return *p.WithTotal
+	}
+
+	return p.Cursor == ""
 }
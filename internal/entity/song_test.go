@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateSongInputUnmarshal(t *testing.T) {
+	date := NewDate(time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		in       string
+		wantLink NullableString
+		wantDate NullableDate
+	}{
+		{name: "absent", in: `{}`},
+		{
+			name:     "null",
+			in:       `{"link":null,"releaseDate":null}`,
+			wantLink: NullableString{Set: true, Null: true},
+			wantDate: NullableDate{Set: true, Null: true},
+		},
+		{
+			name:     "value",
+			in:       `{"link":"https://example.com","releaseDate":"16.07.2006"}`,
+			wantLink: NullableString{Set: true, Value: "https://example.com"},
+			wantDate: NullableDate{Set: true, Value: date},
+		},
+		{
+			name:     "empty string is a value",
+			in:       `{"link":""}`,
+			wantLink: NullableString{Set: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch UpdateSongInput
+			if err := json.Unmarshal([]byte(tt.in), &patch); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.in, err)
+			}
+
+			if patch.Link != tt.wantLink {
+				t.Errorf("Link = %+v, want %+v", patch.Link, tt.wantLink)
+			}
+
+			if patch.ReleaseDate != tt.wantDate {
+				t.Errorf("ReleaseDate = %+v, want %+v", patch.ReleaseDate, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestUpdateSongInputUnmarshalRejects(t *testing.T) {
+	var patch UpdateSongInput
+
+	if err := json.Unmarshal([]byte(`{"link":42}`), &patch); err == nil {
+		t.Error("Unmarshal of a number link succeeded, want error")
+	}
+
+	if err := json.Unmarshal([]byte(`{"releaseDate":"yesterday"}`), &patch); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("Unmarshal of a bad date error = %v, want %v", err, ErrInvalidDate)
+	}
+}
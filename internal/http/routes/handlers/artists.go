@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler godoc
+// @Summary Создать исполнителя
+// @Description Создает новую запись исполнителя
+// @Tags artists
+// @Accept  json
+// @Produce  json
+// @Param artist body entity.ArtistInput true "Данные исполнителя"
+// @Success 201 {object} entity.Artist
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /artists [post]
+// @Router /api/v2/artists [post]
+func (h *Handler) CreateArtist(c *gin.Context) {
+	var input entity.ArtistInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if err := h.validator.Struct(input); err != nil {
+		errorResponse(c, invalid(err))
+
+		return
+	}
+
+	artist, err := h.service.CreateArtist(c.Request.Context(), &input)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, artist)
+}
+
+// Handler godoc
+// @Summary Получить исполнителя
+// @Description Возвращает исполнителя по ID
+// @Tags artists
+// @Produce  json
+// @Param id path int true "ID исполнителя"
+// @Success 200 {object} entity.Artist
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /artists/{id} [get]
+// @Router /api/v2/artists/{id} [get]
+func (h *Handler) GetArtist(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid artist ID"})
+
+		return
+	}
+
+	artist, err := h.service.GetArtistByID(c.Request.Context(), id)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, artist)
+}
+
+// Handler godoc
+// @Summary Получить список исполнителей
+// @Description Возвращает список исполнителей с пагинацией
+// @Tags artists
+// @Produce  json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Лимит элементов на странице" default(10)
+// @Success 200 {object} entity.ArtistsResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /artists [get]
+// @Router /api/v2/artists [get]
+func (h *Handler) GetArtists(c *gin.Context) {
+	pagination := entity.Pagination{Page: 1, Limit: 10}
+
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "Invalid pagination parameters"})
+
+		return
+	}
+
+	if err := h.validator.Struct(pagination); err != nil {
+		errorResponse(c, invalid(err))
+
+		return
+	}
+
+	artists, totalItems, err := h.service.GetAllArtists(c.Request.Context(), pagination)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	totalPages := totalItems / pagination.Limit
+	if totalItems%pagination.Limit != 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, entity.ArtistsResponse{
+		Data:       artists,
+		Page:       pagination.Page,
+		TotalPages: totalPages,
+		TotalItems: totalItems,
+	})
+}
+
+// Handler godoc
+// @Summary Переименовать исполнителя
+// @Description Изменяет название существующего исполнителя. Версии (ETag) всех его песен при этом увеличиваются, так как название группы входит в данные песни
+// @Tags artists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID исполнителя"
+// @Param artist body entity.ArtistInput true "Новые данные исполнителя"
+// @Success 200 {object} entity.Artist
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /artists/{id} [put]
+// @Router /api/v2/artists/{id} [put]
+func (h *Handler) UpdateArtist(c *gin.Context) {
+	var input entity.ArtistInput
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid artist ID"})
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if err := h.validator.Struct(input); err != nil {
+		errorResponse(c, invalid(err))
+
+		return
+	}
+
+	artist, err := h.service.UpdateArtist(c.Request.Context(), id, &input)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, artist)
+}
+
+// Handler godoc
+// @Summary Удалить исполнителя
+// @Description Удаляет исполнителя по ID, если у него нет песен
+// @Tags artists
+// @Produce  json
+// @Param id path int true "ID исполнителя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /artists/{id} [delete]
+// @Router /api/v2/artists/{id} [delete]
+func (h *Handler) DeleteArtist(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid artist ID"})
+
+		return
+	}
+
+	if err := h.service.DeleteArtist(c.Request.Context(), id); err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "artist deleted", "artist_id": id})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/DobryySoul/test-task/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse is the single place where service errors become HTTP
+// statuses. Clients get the text of the domain kind, or the message of a
+// service.ClientError; the full chain is only attached to the gin context,
+// which the request log prints. Validation failures list every broken field
+// and a missing song may come with suggestions.
+func errorResponse(c *gin.Context, err error) {
+	status, kind := http.StatusInternalServerError, error(nil)
+
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		status, kind = http.StatusNotFound, service.ErrNotFound
+	case errors.Is(err, service.ErrConflict):
+		status, kind = http.StatusConflict, service.ErrConflict
+	case errors.Is(err, service.ErrValidation):
+		status, kind = http.StatusUnprocessableEntity, service.ErrValidation
+	case errors.Is(err, service.ErrPreconditionFailed):
+		status, kind = http.StatusPreconditionFailed, service.ErrPreconditionFailed
+	case errors.Is(err, service.ErrUpstream):
+		status, kind = http.StatusBadGateway, service.ErrUpstream
+	case errors.Is(err, service.ErrTimeout):
+		status, kind = http.StatusGatewayTimeout, service.ErrTimeout
+	}
+
+	_ = c.Error(err)
+
+	message := http.StatusText(status)
+	if kind != nil {
+		message = kind.Error()
+	}
+
+	var clientErr *service.ClientError
+	if kind != nil && errors.As(err, &clientErr) {
+		message = clientErr.Message
+	}
+
+	response := entity.ErrorResponse{Error: message, Fields: fieldErrors(err)}
+
+	var suggestionsErr *service.SuggestionsError
+	if errors.As(err, &suggestionsErr) {
+		response.Suggestions = suggestionsErr.Suggestions
+	}
+
+	c.JSON(status, response)
+}
+
+// invalid marks a request that was parsed but failed validation.
+func invalid(err error) error {
+	return fmt.Errorf("%w: %w", service.ErrValidation, err)
+}
+
+// bindFailed answers a request that could not be bound into target. Dates
+// are parsed while binding, so a malformed date is reported as an invalid
+// field like any other validation failure rather than as a malformed
+// request.
+func bindFailed(c *gin.Context, err error, target any, message string) {
+	if errors.Is(err, entity.ErrInvalidDate) {
+		if fields := dateFieldErrors(c, target); len(fields) > 0 {
+			errorResponse(c, invalid(fields))
+
+			return
+		}
+
+		// Текст ошибки разбора даты составлен для клиента и содержит только
+		// его собственное значение.
+		errorResponse(c, &service.ClientError{Kind: service.ErrValidation, Message: err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: message})
+}
+
+// bindErrors are field errors found while binding, before the validator
+// could run.
+type bindErrors []entity.FieldError
+
+func (e bindErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, fe := range e {
+		parts = append(parts, fe.Field+" "+fe.Message)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+var (
+	dateType         = reflect.TypeFor[entity.Date]()
+	nullableDateType = reflect.TypeFor[entity.NullableDate]()
+)
+
+// dateFieldErrors lists the date fields of target whose raw value does not
+// parse. Binding stops at the first bad date, so every date field is checked
+// again: against the JSON body when it was bound with ShouldBindBodyWithJSON,
+// against the query string otherwise.
+func dateFieldErrors(c *gin.Context, target any) bindErrors {
+	var body map[string]json.RawMessage
+	if raw, ok := c.Get(gin.BodyBytesKey); ok {
+		if data, ok := raw.([]byte); ok {
+			_ = json.Unmarshal(data, &body)
+		}
+	}
+
+	t := reflect.TypeOf(target)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var fields bindErrors
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Type != dateType && field.Type != nullableDateType {
+			continue
+		}
+
+		var name string
+		var err error
+
+		if body != nil {
+			name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+
+			value, ok := body[name]
+			if !ok {
+				continue
+			}
+
+			err = json.Unmarshal(value, reflect.New(field.Type).Interface())
+		} else {
+			name, _, _ = strings.Cut(field.Tag.Get("form"), ",")
+
+			value, ok := c.GetQuery(name)
+			if !ok {
+				continue
+			}
+
+			_, err = entity.ParseDate(value)
+		}
+
+		if err != nil {
+			fields = append(fields, entity.FieldError{Field: name, Rule: "date", Message: dateMessage})
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/DobryySoul/test-task/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "repository details are hidden",
+			err:         fmt.Errorf("%w: CreateArtist: %w", service.ErrConflict, errors.New(`duplicate key value violates unique constraint "artists_group_name_key"`)),
+			wantStatus:  http.StatusConflict,
+			wantMessage: "conflict",
+		},
+		{
+			name:        "not found",
+			err:         fmt.Errorf("%w: GetByID: record not found", service.ErrNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "not found",
+		},
+		{
+			name:        "client message",
+			err:         &service.ClientError{Kind: service.ErrNotFound, Message: "song has 2 verses"},
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "song has 2 verses",
+		},
+		{
+			name:        "timeout",
+			err:         fmt.Errorf("%w: GetAllSongs: query timed out", service.ErrTimeout),
+			wantStatus:  http.StatusGatewayTimeout,
+			wantMessage: "timeout",
+		},
+		{
+			name:        "unknown error",
+			err:         errors.New("GetAllSongs: ошибка при выполнении запроса"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: http.StatusText(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			errorResponse(c, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var body entity.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+
+			if body.Error != tt.wantMessage {
+				t.Errorf("error = %q, want %q", body.Error, tt.wantMessage)
+			}
+
+			if len(c.Errors) != 1 || !errors.Is(c.Errors[0].Err, tt.err) {
+				t.Errorf("context errors = %v, want the original error", c.Errors)
+			}
+		})
+	}
+}
+
+func TestBindFailedDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		bind       func(c *gin.Context) (any, error)
+		wantFields []entity.FieldError
+	}{
+		{
+			name:   "json body",
+			method: http.MethodPut,
+			target: "/",
+			body:   `{"artist_id":1,"song_name":"Uprising","release_date":"yesterday"}`,
+			bind: func(c *gin.Context) (any, error) {
+				var input entity.SongInput
+
+				return &input, c.ShouldBindBodyWithJSON(&input)
+			},
+			wantFields: []entity.FieldError{{Field: "release_date", Rule: "date", Message: dateMessage}},
+		},
+		{
+			name:   "merge patch",
+			method: http.MethodPatch,
+			target: "/",
+			body:   `{"releaseDate":20060716}`,
+			bind: func(c *gin.Context) (any, error) {
+				var patch entity.UpdateSongInput
+
+				return &patch, c.ShouldBindBodyWithJSON(&patch)
+			},
+			wantFields: []entity.FieldError{{Field: "releaseDate", Rule: "date", Message: dateMessage}},
+		},
+		{
+			name:   "query filter",
+			method: http.MethodGet,
+			target: "/?release_date_from=2006-07-16&release_date_to=16/07/2006&release_date=",
+			bind: func(c *gin.Context) (any, error) {
+				var query entity.SongFilterQuery
+
+				return &query, c.ShouldBindQuery(&query)
+			},
+			wantFields: []entity.FieldError{
+				{Field: "release_date", Rule: "date", Message: dateMessage},
+				{Field: "release_date_to", Rule: "date", Message: dateMessage},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+
+			target, err := tt.bind(c)
+			if err == nil {
+				t.Fatal("bind succeeded, want a date error")
+			}
+
+			bindFailed(c, err, target, err.Error())
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+			}
+
+			var body entity.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+
+			if !reflect.DeepEqual(body.Fields, tt.wantFields) {
+				t.Errorf("fields = %+v, want %+v", body.Fields, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/DobryySoul/test-task/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var errVersionMismatch = &service.ClientError{
+	Kind:    service.ErrPreconditionFailed,
+	Message: "If-Match does not match the current song version",
+}
+
+// setETag exposes the song version as a strong entity tag.
+func setETag(c *gin.Context, song *entity.Song) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(song.Version)))
+}
+
+// ifMatch returns the versions allowed by the If-Match header, a list of
+// entity tags (RFC 9110), or nil when the header is absent or "*". Weak and
+// malformed tags never match; a header without any usable tag fails.
+func ifMatch(c *gin.Context) ([]int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var versions []int
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, errVersionMismatch
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/DobryySoul/test-task/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    []int
+		wantErr bool
+	}{
+		{header: "", want: nil},
+		{header: "*", want: nil},
+		{header: `"3"`, want: []int{3}},
+		{header: `"3", "4"`, want: []int{3, 4}},
+		{header: `"3",W/"4"`, want: []int{3}},
+		{header: ` "3" ,, "x", "5"`, want: []int{3, 5}},
+		{header: `W/"3"`, wantErr: true},
+		{header: `3`, wantErr: true},
+		{header: `"abc"`, wantErr: true},
+		{header: `"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("PUT", "/", nil)
+			c.Request.Header.Set("If-Match", tt.header)
+
+			got, err := ifMatch(c)
+			if tt.wantErr {
+				if !errors.Is(err, service.ErrPreconditionFailed) {
+					t.Fatalf("ifMatch(%q) error = %v, want %v", tt.header, err, service.ErrPreconditionFailed)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ifMatch(%q): %v", tt.header, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// maxArtistIDs bounds the artist_id list so the IN clause stays small.
+const maxArtistIDs = 100
+
+// songSortFields whitelists the keys of the sort parameter.
+var songSortFields = []string{"song_name", "release_date", "song_id", "group", "artist_id"}
+
+// defaultSongSort keeps the historical order of the list.
+var defaultSongSort = []entity.SortField{{Name: "song_name"}, {Name: "release_date"}}
+
+// parseNameMatch splits an "op:value" filter. A prefix that is not a known
+// operator belongs to the value, so "AC/DC: Live" is matched as is.
+func parseNameMatch(raw string, def entity.MatchOp) *entity.NameMatch {
+	if raw == "" {
+		return nil
+	}
+
+	if op, value, ok := strings.Cut(raw, ":"); ok {
+		switch entity.MatchOp(op) {
+		case entity.MatchExact, entity.MatchPrefix, entity.MatchContains:
+			return &entity.NameMatch{Op: entity.MatchOp(op), Value: value}
+		}
+	}
+
+	return &entity.NameMatch{Op: def, Value: raw}
+}
+
+// parseArtistIDs accepts both artist_id=1,2 and repeated artist_id params.
+func parseArtistIDs(raw []string) ([]int, error) {
+	var ids []int
+
+	for _, item := range raw {
+		for _, part := range strings.Split(item, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			id, err := strconv.Atoi(part)
+			if err != nil || id < 1 {
+				return nil, fmt.Errorf("invalid artist id %q", part)
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// songFilter compiles validated query parameters into a filter. Song names
+// match exactly by default, groups and text by substring.
+func songFilter(query entity.SongFilterQuery) entity.SongFilter {
+	groupOp := entity.MatchContains
+	if query.GroupExact {
+		groupOp = entity.MatchExact
+	}
+
+	// Список уже проверен в validateSongFilter.
+	artistIDs, _ := parseArtistIDs(query.ArtistID)
+
+	return entity.SongFilter{
+		Group:           parseNameMatch(query.Group, groupOp),
+		Song:            parseNameMatch(query.Song, entity.MatchExact),
+		Text:            parseNameMatch(query.Text, entity.MatchContains),
+		ReleaseDate:     optionalDate(query.ReleaseDate),
+		ReleaseDateFrom: optionalDate(query.ReleaseDateFrom),
+		ReleaseDateTo:   optionalDate(query.ReleaseDateTo),
+		Link:            optional(query.Link),
+		HasLink:         query.HasLink,
+		ArtistIDs:       artistIDs,
+	}
+}
+
+// parseSort parses "-release_date,song_name": a leading "-" sorts the key
+// in descending order. Keys must be whitelisted and may not repeat.
+func parseSort(raw string) ([]entity.SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return defaultSongSort, nil
+	}
+
+	var fields []entity.SortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		name, desc := strings.CutPrefix(part, "-")
+
+		if !slices.Contains(songSortFields, name) || seen[name] {
+			return nil, fmt.Errorf("invalid sort key %q", part)
+		}
+
+		seen[name] = true
+		fields = append(fields, entity.SortField{Name: name, Desc: desc})
+	}
+
+	return fields, nil
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// stringValue is the inverse of optional: nil becomes the empty string.
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func optionalDate(value entity.Date) *entity.Date {
+	if value.IsZero() {
+		return nil
+	}
+
+	return &value
+}
+
+// bindSongList binds and validates the filter, sort and page parameters of
+// a song list request. On failure it has already answered the request.
+func (h *Handler) bindSongList(c *gin.Context) (entity.SongFilter, []entity.SortField, entity.SongPage, bool) {
+	var query entity.SongFilterQuery
+	var page entity.SongPage
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		bindFailed(c, err, &query, "Invalid query parameters")
+
+		return entity.SongFilter{}, nil, page, false
+	}
+
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "Invalid pagination parameters"})
+
+		return entity.SongFilter{}, nil, page, false
+	}
+
+	if err := h.validator.Struct(query); err != nil {
+		errorResponse(c, invalid(err))
+
+		return entity.SongFilter{}, nil, page, false
+	}
+
+	if err := h.validator.Struct(page); err != nil {
+		errorResponse(c, invalid(err))
+
+		return entity.SongFilter{}, nil, page, false
+	}
+
+	// Ключи уже проверены в validateSongFilter.
+	order, _ := parseSort(query.Sort)
+
+	return songFilter(query), order, page, true
+}
+
+// songsResponse renders a page of songs. The page number is only meaningful
+// without a cursor, and totals only when they were counted.
+func songsResponse(list *entity.SongList, page entity.SongPage) entity.SongsResponse {
+	response := entity.SongsResponse{
+		Data:       list.Songs,
+		TotalItems: list.Total,
+		NextCursor: list.NextCursor,
+	}
+
+	if page.Cursor == "" {
+		response.Page = page.Page
+	}
+
+	if list.Total != nil {
+		totalPages := *list.Total / page.Limit
+		if *list.Total%page.Limit != 0 {
+			totalPages++
+		}
+
+		response.TotalPages = &totalPages
+	}
+
+	return response
+}
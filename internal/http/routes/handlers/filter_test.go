@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+func TestParseNameMatch(t *testing.T) {
+	tests := []struct {
+		raw  string
+		def  entity.MatchOp
+		want *entity.NameMatch
+	}{
+		{raw: "", def: entity.MatchExact, want: nil},
+		{raw: "Muse", def: entity.MatchExact, want: &entity.NameMatch{Op: entity.MatchExact, Value: "Muse"}},
+		{raw: "Muse", def: entity.MatchContains, want: &entity.NameMatch{Op: entity.MatchContains, Value: "Muse"}},
+		{raw: "prefix:Mu", def: entity.MatchExact, want: &entity.NameMatch{Op: entity.MatchPrefix, Value: "Mu"}},
+		{raw: "contains:use", def: entity.MatchExact, want: &entity.NameMatch{Op: entity.MatchContains, Value: "use"}},
+		{raw: "exact:Muse", def: entity.MatchContains, want: &entity.NameMatch{Op: entity.MatchExact, Value: "Muse"}},
+		{raw: "exact:", def: entity.MatchContains, want: &entity.NameMatch{Op: entity.MatchExact, Value: ""}},
+		{raw: "AC/DC: Live", def: entity.MatchExact, want: &entity.NameMatch{Op: entity.MatchExact, Value: "AC/DC: Live"}},
+		{raw: "prefix:a:b", def: entity.MatchExact, want: &entity.NameMatch{Op: entity.MatchPrefix, Value: "a:b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseNameMatch(tt.raw, tt.def); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNameMatch(%q, %q) = %+v, want %+v", tt.raw, tt.def, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArtistIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     []string
+		want    []int
+		wantErr bool
+	}{
+		{name: "absent", raw: nil, want: nil},
+		{name: "comma separated", raw: []string{"1,2, 3"}, want: []int{1, 2, 3}},
+		{name: "repeated", raw: []string{"1", "2,3"}, want: []int{1, 2, 3}},
+		{name: "empty items", raw: []string{",1,,"}, want: []int{1}},
+		{name: "not a number", raw: []string{"1,x"}, wantErr: true},
+		{name: "zero", raw: []string{"0"}, wantErr: true},
+		{name: "negative", raw: []string{"-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseArtistIDs(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArtistIDs(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArtistIDs(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSongFilter(t *testing.T) {
+	hasLink := true
+
+	got := songFilter(entity.SongFilterQuery{
+		Group:    "Muse",
+		Song:     "prefix:Super",
+		Text:     "baby",
+		Link:     "https://example.com",
+		HasLink:  &hasLink,
+		ArtistID: []string{"1,2"},
+	})
+
+	link := "https://example.com"
+	want := entity.SongFilter{
+		Group:     &entity.NameMatch{Op: entity.MatchContains, Value: "Muse"},
+		Song:      &entity.NameMatch{Op: entity.MatchPrefix, Value: "Super"},
+		Text:      &entity.NameMatch{Op: entity.MatchContains, Value: "baby"},
+		Link:      &link,
+		HasLink:   &hasLink,
+		ArtistIDs: []int{1, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("songFilter = %+v, want %+v", got, want)
+	}
+
+	exact := songFilter(entity.SongFilterQuery{Group: "Muse", GroupExact: true})
+	if want := (&entity.NameMatch{Op: entity.MatchExact, Value: "Muse"}); !reflect.DeepEqual(exact.Group, want) {
+		t.Errorf("songFilter with group_exact Group = %+v, want %+v", exact.Group, want)
+	}
+}
+
+func TestValidateSongFilter(t *testing.T) {
+	v := NewValidator()
+
+	tooManyIDs := make([]string, maxArtistIDs+1)
+	for i := range tooManyIDs {
+		tooManyIDs[i] = "1"
+	}
+
+	tests := []struct {
+		name     string
+		query    entity.SongFilterQuery
+		wantRule string
+	}{
+		{name: "valid", query: entity.SongFilterQuery{Group: "prefix:Mu", ArtistID: []string{"1,2"}}},
+		{name: "long value after operator", query: entity.SongFilterQuery{Song: "prefix:" + strings.Repeat("a", 256)}, wantRule: "max"},
+		{name: "bad artist id", query: entity.SongFilterQuery{ArtistID: []string{"x"}}, wantRule: "artist_ids"},
+		{name: "too many artist ids", query: entity.SongFilterQuery{ArtistID: tooManyIDs}, wantRule: "max"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := fieldErrors(v.Struct(tt.query))
+
+			switch {
+			case tt.wantRule == "" && len(fields) > 0:
+				t.Errorf("unexpected errors: %+v", fields)
+			case tt.wantRule != "" && (len(fields) != 1 || fields[0].Rule != tt.wantRule):
+				t.Errorf("errors = %+v, want one %q error", fields, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []entity.SortField
+		wantErr bool
+	}{
+		{raw: "", want: defaultSongSort},
+		{raw: "  ", want: defaultSongSort},
+		{raw: "release_date", want: []entity.SortField{{Name: "release_date"}}},
+		{
+			raw:  "-release_date, song_name,-song_id",
+			want: []entity.SortField{{Name: "release_date", Desc: true}, {Name: "song_name"}, {Name: "song_id", Desc: true}},
+		},
+		{raw: "group,artist_id", want: []entity.SortField{{Name: "group"}, {Name: "artist_id"}}},
+		{raw: "song_text", wantErr: true},
+		{raw: "song_name,-song_name", wantErr: true},
+		{raw: "song_name,", wantErr: true},
+		{raw: "--song_name", wantErr: true},
+		{raw: "+song_name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseSort(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSort(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSort(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSongFilterSort(t *testing.T) {
+	v := NewValidator()
+
+	fields := fieldErrors(v.Struct(entity.SongFilterQuery{Sort: "-song_text"}))
+	if len(fields) != 1 || fields[0].Field != "sort" || fields[0].Rule != "sort" {
+		t.Errorf("errors = %+v, want one sort error", fields)
+	}
+}
@@ -1,24 +1,34 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/DobryySoul/test-task/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type Service interface {
-	CreateSong(song *entity.CreateSongInput) error
-	GetByGroupAndSongName(group, songName string) (*entity.Song, error)
-	// UpdateSong(song *entity.Song, ID int) error
-	UpdateFieldSong(updateField *entity.UpdateSongInput, song *entity.Song) error
-	Delete(id int) error
-	GetSongByID(id int) (*entity.Song, error)
-	GetSongText(id int) ([]string, error)
-	GetAllSongs(filter entity.SongFilter, pagination entity.Pagination) ([]entity.Song, int, error)
+	CreateSong(ctx context.Context, input *entity.CreateSongInput) (*entity.Song, error)
+	FindSong(ctx context.Context, group, songName string, fuzzy bool) (*entity.Song, error)
+	UpdateSong(ctx context.Context, id int, input *entity.SongInput, versions []int) (*entity.Song, error)
+	UpdateFieldSong(ctx context.Context, id int, patch *entity.UpdateSongInput, versions []int) (*entity.Song, error)
+	UpdateFieldSongByName(ctx context.Context, group, songName string, patch *entity.UpdateSongInput, versions []int) (*entity.Song, error)
+	Delete(ctx context.Context, id int, versions []int) error
+	DeleteByName(ctx context.Context, group, songName string, versions []int) (*entity.Song, error)
+	GetSongByID(ctx context.Context, id int) (*entity.Song, error)
+	GetSongText(ctx context.Context, id int) (*entity.Song, []string, error)
+	GetAllSongs(ctx context.Context, filter entity.SongFilter, order []entity.SortField, page entity.SongPage) (*entity.SongList, error)
+	CreateArtist(ctx context.Context, input *entity.ArtistInput) (*entity.Artist, error)
+	GetArtistByID(ctx context.Context, id int) (*entity.Artist, error)
+	GetAllArtists(ctx context.Context, pagination entity.Pagination) ([]entity.Artist, int, error)
+	UpdateArtist(ctx context.Context, id int, input *entity.ArtistInput) (*entity.Artist, error)
+	DeleteArtist(ctx context.Context, id int) error
+	Search(ctx context.Context, query string, pagination entity.Pagination) ([]entity.SearchResult, int, error)
 }
 
 type Handler struct {
@@ -34,35 +44,99 @@ func NewHandler(service Service, validator validator.Validate) *Handler {
 	}
 }
 
+// Handler godoc
+// @Summary Создать новую песню
+// @Description Создает новую запись песни, дата выхода, текст и ссылка запрашиваются во внешнем API. Неизвестный исполнитель создается автоматически
+// @Tags songs
+// @Accept  json
+// @Produce  json
+// @Param song body entity.CreateSongInput true "Группа и название песни"
+// @Success 200 {object} map[string]entity.Song
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Failure 502 {object} entity.ErrorResponse
+// @DeprecatedRouter /create-song [post]
 func (h *Handler) CreateSong(c *gin.Context) {
-	var song entity.CreateSongInput
+	song, ok := h.createSong(c)
+	if !ok {
+		return
+	}
 
-	if err := c.ShouldBindJSON(&song); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	c.JSON(http.StatusOK, gin.H{"created data": song})
+}
 
+// Handler godoc
+// @Summary Создать новую песню
+// @Description Создает новую запись песни, дата выхода, текст и ссылка запрашиваются во внешнем API. Неизвестный исполнитель создается автоматически
+// @Tags songs
+// @Accept  json
+// @Produce  json
+// @Param song body entity.CreateSongInput true "Группа и название песни"
+// @Success 201 {object} entity.Song
+// @Header 201 {string} Location "Адрес созданной песни"
+// @Header 201 {string} ETag "Версия песни"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Failure 502 {object} entity.ErrorResponse
+// @Router /api/v2/songs [post]
+func (h *Handler) PostSong(c *gin.Context) {
+	song, ok := h.createSong(c)
+	if !ok {
 		return
 	}
 
-	if err := h.service.CreateSong(&song); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	c.Header("Location", fmt.Sprintf("/api/v2/songs/%d", song.SongID))
+	setETag(c, song)
+	c.JSON(http.StatusCreated, song)
+}
 
-		return
+// createSong binds, validates and creates the song of a create request. On
+// failure it has already answered the request.
+func (h *Handler) createSong(c *gin.Context) (*entity.Song, bool) {
+	var input entity.CreateSongInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		bindFailed(c, err, &input, err.Error())
+
+		return nil, false
 	}
 
-	c.JSON(http.StatusOK, gin.H{"created data": song})
+	if err := h.validator.Struct(input); err != nil {
+		errorResponse(c, invalid(err))
+
+		return nil, false
+	}
+
+	song, err := h.service.CreateSong(c.Request.Context(), &input)
+	if err != nil {
+		errorResponse(c, err)
+
+		return nil, false
+	}
+
+	return song, true
 }
 
 // Handler godoc
 // @Summary Получить песню по группе и названию
-// @Description Возвращает информацию о песне по группе и названию
+// @Description Возвращает информацию о песне по группе и названию. Если точного совпадения нет, 404 содержит похожие песни в suggestions, а с fuzzy=true возвращается самая похожая песня
 // @Tags songs
 // @Produce  json
 // @Param group query string true "Название группы"
 // @Param song query string true "Название песни"
+// @Param fuzzy query bool false "Вернуть самую похожую песню, если точного совпадения нет"
 // @Success 200 {object} entity.GetSongResponse
+// @Header 200 {string} ETag "Версия песни"
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
-// @Router /info [get]
+// @DeprecatedRouter /info [get]
 func (h *Handler) GetSongByQuery(c *gin.Context) {
 	var response entity.GetSongResponse
 
@@ -70,132 +144,189 @@ func (h *Handler) GetSongByQuery(c *gin.Context) {
 	songName := c.Query("song")
 
 	if group == "" || songName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "group and song parameters are required"})
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "group and song parameters are required"})
+		return
+	}
+
+	fuzzy, err := strconv.ParseBool(c.DefaultQuery("fuzzy", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "fuzzy must be a boolean"})
 		return
 	}
 
-	song, err := h.service.GetByGroupAndSongName(group, songName)
+	song, err := h.service.FindSong(c.Request.Context(), group, songName, fuzzy)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 
 		return
 	}
 	response = entity.GetSongResponse{
 		SongName:    song.SongName,
-		Group:       group,
-		ReleaseDate: song.ReleaseDate,
-		Text:        song.SongText,
-		Link:        song.Link,
+		Group:       song.Group,
+		ReleaseDate: song.ReleaseDate.String(),
+		Text:        stringValue(song.SongText),
+		Link:        stringValue(song.Link),
 	}
 
+	setETag(c, song)
 	c.JSON(http.StatusOK, response)
 }
 
-// // Handler godoc
-// // @Summary Обновить песню
-// // @Description Обновляет существующую запись песни
-// // @Tags songs
-// // @Accept  json
-// // @Produce  json
-// // @Param id path int true "ID песни"
-// // @Param song body entity.Song true "Обновленные данные песни"
-// // @Success 200 {object} entity.Song
-// // @Failure 400 {object} entity.ErrorResponse
-// // @Failure 500 {object} entity.ErrorResponse
-// // @Router /update-song/{id} [put]
-// func (h *Handler) UpdateSong(c *gin.Context) {
-// 	var song entity.Song
+// Handler godoc
+// @Summary Обновить песню
+// @Description Полностью заменяет данные существующей песни, в том числе исполнителя
+// @Tags songs
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID песни"
+// @Param If-Match header string false "Ожидаемые версии песни: ETag или список ETag через запятую"
+// @Param song body entity.SongInput true "Новые данные песни"
+// @Success 200 {object} entity.Song
+// @Header 200 {string} ETag "Новая версия песни"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 412 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /update-song/{id} [put]
+// @Router /api/v2/songs/{id} [put]
+func (h *Handler) UpdateSong(c *gin.Context) {
+	var input entity.SongInput
+
+	ID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid song ID"})
 
-// 	ID, err := strconv.Atoi(c.Param("id"))
-// 	if err != nil {
-// 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-// 		return
-// 	}
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		bindFailed(c, err, &input, err.Error())
 
-// 	if err := c.ShouldBindJSON(&song); err != nil {
-// 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-// 		return
-// 	}
+	if err := h.validator.Struct(input); err != nil {
+		errorResponse(c, invalid(err))
 
-// 	err = h.service.UpdateSong(&song, ID)
-// 	if err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-// 		return
-// 	}
+	versions, err := ifMatch(c)
+	if err != nil {
+		errorResponse(c, err)
 
-// 	c.JSON(http.StatusOK, song)
-// }
+		return
+	}
 
-func (h *Handler) UpdateFieldSong(c *gin.Context) {
-	var song *entity.Song
+	song, err := h.service.UpdateSong(c.Request.Context(), ID, &input, versions)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
 
+	setETag(c, song)
+	c.JSON(http.StatusOK, song)
+}
+
+// Handler godoc
+// @Summary Частично изменить песню
+// @Description Применяет JSON Merge Patch к песне, найденной по группе и названию
+// @Tags songs
+// @Accept  json
+// @Accept  application/merge-patch+json
+// @Produce  json
+// @Param group query string true "Название группы"
+// @Param song_name query string true "Название песни"
+// @Param If-Match header string false "Ожидаемые версии песни: ETag или список ETag через запятую"
+// @Param song body entity.UpdateSongInput true "Изменяемые поля"
+// @Success 200 {object} map[string]entity.Song
+// @Header 200 {string} ETag "Новая версия песни"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 412 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @DeprecatedRouter /update-song [patch]
+func (h *Handler) UpdateFieldSong(c *gin.Context) {
 	group := c.Query("group")
 	songName := c.Query("song_name")
 
 	if group == "" || songName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "group and song parameters are required"})
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "group and song parameters are required"})
 
 		return
 	}
 
-	song, err := h.service.GetByGroupAndSongName(group, songName)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var patch entity.UpdateSongInput
+
+	if err := c.ShouldBindBodyWithJSON(&patch); err != nil {
+		bindFailed(c, err, &patch, err.Error())
 
 		return
 	}
 
-	var UpdateFieldSong *entity.UpdateSongInput
+	if err := h.validator.Struct(patch); err != nil {
+		errorResponse(c, invalid(err))
 
-	if err := c.ShouldBindJSON(&UpdateFieldSong); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	versions, err := ifMatch(c)
+	if err != nil {
+		errorResponse(c, err)
 
 		return
 	}
 
-	err = h.service.UpdateFieldSong(UpdateFieldSong, song)
+	song, err := h.service.UpdateFieldSongByName(c.Request.Context(), group, songName, &patch, versions)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 
 		return
 	}
 
+	setETag(c, song)
 	c.JSON(http.StatusOK, gin.H{"update data song": song})
 }
 
 // Handler godoc
 // @Summary Удалить песню
-// @Description Удаляет запись песни по ID
+// @Description Удаляет песню, найденную по группе и названию
 // @Tags songs
 // @Produce  json
-// @Param id path int true "ID песни"
-// @Success 200 {object} map[string]string
+// @Param group query string true "Название группы"
+// @Param song_name query string true "Название песни"
+// @Param If-Match header string false "Ожидаемые версии песни: ETag или список ETag через запятую"
+// @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 412 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
-// @Router /delete-song/{id} [delete]
+// @DeprecatedRouter /delete-song [delete]
 func (h *Handler) DeleteSong(c *gin.Context) {
 	group := c.Query("group")
 	songName := c.Query("song_name")
 
 	if group == "" || songName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "group and song parameters are required"})
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "group and song parameters are required"})
 
 		return
 	}
 
-	song, err := h.service.GetByGroupAndSongName(group, songName)
+	versions, err := ifMatch(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 
 		return
 	}
 
-	err = h.service.Delete(song.SongID)
+	song, err := h.service.DeleteByName(c.Request.Context(), group, songName, versions)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 
 		return
 	}
@@ -205,21 +336,22 @@ func (h *Handler) DeleteSong(c *gin.Context) {
 
 // Handler godoc
 // @Summary Получить текст песни
-// @Description Возвращает текст песни с пагинацией
+// @Description Возвращает куплеты песни с пагинацией. Куплеты разделяются пустыми строками; страница за последней - 404
 // @Tags songs
 // @Produce  json
 // @Param id path int true "ID песни"
 // @Param page query int false "Номер страницы" default(1)
-// @Param limit query int false "Лимит элементов на странице" default(2)
-// @Success 200 {object} map[string]interface{} "Пример ответа: {"song": "название", "verses": [...]}"
+// @Param limit query int false "Куплетов на странице" default(2)
+// @Success 200 {object} entity.VersesResponse
 // @Failure 400 {object} entity.ErrorResponse
 // @Failure 404 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
-// @Router /song-text/{id}/text [get]
+// @DeprecatedRouter /song-text/{id}/text [get]
+// @Router /api/v2/songs/{id}/verses [get]
 func (h *Handler) GetSongText(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid song ID"})
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid song ID"})
 
 		return
 	}
@@ -228,40 +360,98 @@ func (h *Handler) GetSongText(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "2"))
 
 	if page < 1 || limit < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination parameters"})
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid pagination parameters"})
 
 		return
 	}
 
-	text, err := h.service.GetSongText(id)
+	song, verses, err := h.service.GetSongText(c.Request.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "song not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		errorResponse(c, err)
+
+		return
+	}
+
+	totalVerses := len(verses)
+	totalPages := totalVerses / limit
+	if totalVerses%limit != 0 {
+		totalPages++
+	}
+
+	// Первая страница есть всегда, даже у песни без текста.
+	if page > max(totalPages, 1) {
+		errorResponse(c, &service.ClientError{
+			Kind:    service.ErrNotFound,
+			Message: fmt.Sprintf("page %d is past the last page %d", page, totalPages),
+		})
 
 		return
 	}
 
-	totalText := len(text)
 	start := (page - 1) * limit
-	end := start + limit
+	end := min(start+limit, totalVerses)
+
+	c.JSON(http.StatusOK, entity.VersesResponse{
+		SongID:      song.SongID,
+		Song:        song.SongName,
+		Text:        verses[start:end],
+		Page:        page,
+		Limit:       limit,
+		TotalVerses: totalVerses,
+		TotalPages:  totalPages,
+	})
+}
+
+// Handler godoc
+// @Summary Получить куплет песни
+// @Description Возвращает один куплет песни по номеру, начиная с 1
+// @Tags songs
+// @Produce  json
+// @Param id path int true "ID песни"
+// @Param index path int true "Номер куплета"
+// @Success 200 {object} entity.VerseResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v2/songs/{id}/verses/{index} [get]
+func (h *Handler) GetSongVerse(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid song ID"})
 
-	if start > totalText {
-		start = totalText
+		return
 	}
-	if end > totalText {
-		end = totalText
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 1 {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid verse index"})
+
+		return
 	}
 
-	song, _ := h.service.GetSongByID(id)
-	response := gin.H{
-		"song": song.SongName,
-		"text": text[start:end],
+	song, verses, err := h.service.GetSongText(c.Request.Context(), id)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	if index > len(verses) {
+		errorResponse(c, &service.ClientError{
+			Kind:    service.ErrNotFound,
+			Message: fmt.Sprintf("song has %d verses", len(verses)),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.VerseResponse{
+		SongID:      song.SongID,
+		Song:        song.SongName,
+		Index:       index,
+		Text:        verses[index-1],
+		TotalVerses: len(verses),
+	})
 }
 
 // Handler godoc
@@ -269,61 +459,222 @@ func (h *Handler) GetSongText(c *gin.Context) {
 // @Description Возвращает список песен с фильтрацией и пагинацией
 // @Tags songs
 // @Produce  json
-// @Param group query string false "Фильтр по группе"
-// @Param song query string false "Фильтр по названию песни"
-// @Param release_date query string false "Фильтр по дате выпуска"
-// @Param text query string false "Фильтр по тексту"
+// @Param group query string false "Фильтр по группе: [exact:|prefix:|contains:]значение, по умолчанию contains без учета регистра"
+// @Param group_exact query bool false "Точное совпадение названия группы без оператора"
+// @Param song query string false "Фильтр по названию песни: [exact:|prefix:|contains:]значение, по умолчанию exact"
+// @Param release_date query string false "Фильтр по дате выпуска (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param release_date_from query string false "Дата выпуска не раньше (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param release_date_to query string false "Дата выпуска не позже (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param text query string false "Фильтр по тексту: [exact:|prefix:|contains:]значение, по умолчанию contains"
 // @Param link query string false "Фильтр по ссылке"
+// @Param has_link query bool false "Только песни со ссылкой (true) или без нее (false)"
+// @Param artist_id query string false "ID исполнителей через запятую"
+// @Param sort query string false "Сортировка: song_name, release_date, song_id, group, artist_id через запятую, - для убывания" default(song_name,release_date)
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Лимит элементов на странице" default(10)
+// @Param cursor query string false "Курсор next_cursor из предыдущего ответа, заменяет page"
+// @Param with_total query bool false "Считать total_items и total_pages (по умолчанию только без cursor)"
 // @Success 200 {object} entity.SongsResponse
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
-// @Router /songs-with-filter [get]
+// @DeprecatedRouter /songs-with-filter [get]
+// @Router /api/v2/songs [get]
 func (h *Handler) GetSongs(c *gin.Context) {
-	var filter entity.SongFilter
-	var pagination entity.Pagination
+	filter, order, page, ok := h.bindSongList(c)
+	if !ok {
+		return
+	}
 
-	if err := c.ShouldBindQuery(&filter); err != nil {
-		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "Invalid query parameters"})
+	list, err := h.service.GetAllSongs(c.Request.Context(), filter, order, page)
+	if err != nil {
+		errorResponse(c, err)
 
 		return
 	}
 
-	if err := c.ShouldBindQuery(&pagination); err != nil {
-		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "Invalid pagination parameters"})
+	c.JSON(http.StatusOK, songsResponse(list, page))
+}
+
+// Handler godoc
+// @Summary Получить песню
+// @Description Возвращает песню по ID
+// @Tags songs
+// @Produce  json
+// @Param id path int true "ID песни"
+// @Success 200 {object} entity.Song
+// @Header 200 {string} ETag "Версия песни"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v2/songs/{id} [get]
+func (h *Handler) GetSong(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid song ID"})
 
 		return
 	}
 
-	if err := h.validator.Struct(pagination); err != nil {
-		c.JSON(http.StatusBadRequest, entity.ErrorResponse{
-			Error: "Validation error: " + err.Error(),
-		})
+	song, err := h.service.GetSongByID(c.Request.Context(), id)
+	if err != nil {
+		errorResponse(c, err)
 
 		return
 	}
 
-	songs, totalItems, err := h.service.GetAllSongs(filter, pagination)
+	setETag(c, song)
+	c.JSON(http.StatusOK, song)
+}
+
+// Handler godoc
+// @Summary Частично изменить песню
+// @Description Применяет JSON Merge Patch к песне по ID
+// @Tags songs
+// @Accept  json
+// @Accept  application/merge-patch+json
+// @Produce  json
+// @Param id path int true "ID песни"
+// @Param If-Match header string false "Ожидаемые версии песни: ETag или список ETag через запятую"
+// @Param song body entity.UpdateSongInput true "Изменяемые поля"
+// @Success 200 {object} entity.Song
+// @Header 200 {string} ETag "Новая версия песни"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 412 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v2/songs/{id} [patch]
+func (h *Handler) PatchSong(c *gin.Context) {
+	var patch entity.UpdateSongInput
+
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, entity.ErrorResponse{
-			Error: "Failed to retrieve songs",
-		})
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid song ID"})
 
 		return
 	}
 
-	totalPages := totalItems / pagination.Limit
-	if totalItems%pagination.Limit != 0 {
-		totalPages++
+	if err := c.ShouldBindBodyWithJSON(&patch); err != nil {
+		bindFailed(c, err, &patch, err.Error())
+
+		return
 	}
 
-	response := entity.SongsResponse{
-		Data:       songs,
-		Page:       pagination.Page,
-		TotalPages: totalPages,
-		TotalItems: totalItems,
+	if err := h.validator.Struct(patch); err != nil {
+		errorResponse(c, invalid(err))
+
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	versions, err := ifMatch(c)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	song, err := h.service.UpdateFieldSong(c.Request.Context(), id, &patch, versions)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	setETag(c, song)
+	c.JSON(http.StatusOK, song)
+}
+
+// Handler godoc
+// @Summary Удалить песню по ID
+// @Description Удаляет запись песни по ID
+// @Tags songs
+// @Produce  json
+// @Param id path int true "ID песни"
+// @Param If-Match header string false "Ожидаемые версии песни: ETag или список ETag через запятую"
+// @Success 204
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 412 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v2/songs/{id} [delete]
+func (h *Handler) DeleteSongByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid song ID"})
+
+		return
+	}
+
+	versions, err := ifMatch(c)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id, versions); err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Handler godoc
+// @Summary Получить песни исполнителя
+// @Description Возвращает песни исполнителя с фильтрацией и пагинацией
+// @Tags artists
+// @Produce  json
+// @Param id path int true "ID исполнителя"
+// @Param song query string false "Фильтр по названию песни: [exact:|prefix:|contains:]значение, по умолчанию exact"
+// @Param release_date query string false "Фильтр по дате выпуска (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param release_date_from query string false "Дата выпуска не раньше (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param release_date_to query string false "Дата выпуска не позже (YYYY-MM-DD или DD.MM.YYYY)"
+// @Param text query string false "Фильтр по тексту: [exact:|prefix:|contains:]значение, по умолчанию contains"
+// @Param link query string false "Фильтр по ссылке"
+// @Param has_link query bool false "Только песни со ссылкой (true) или без нее (false)"
+// @Param sort query string false "Сортировка: song_name, release_date, song_id, group, artist_id через запятую, - для убывания" default(song_name,release_date)
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Лимит элементов на странице" default(10)
+// @Param cursor query string false "Курсор next_cursor из предыдущего ответа, заменяет page"
+// @Param with_total query bool false "Считать total_items и total_pages (по умолчанию только без cursor)"
+// @Success 200 {object} entity.SongsResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v2/artists/{id}/songs [get]
+func (h *Handler) GetArtistSongs(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "invalid artist ID"})
+
+		return
+	}
+
+	filter, order, page, ok := h.bindSongList(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.GetArtistByID(c.Request.Context(), id); err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	filter.Group = nil
+	filter.ArtistIDs = []int{id}
+
+	list, err := h.service.GetAllSongs(c.Request.Context(), filter, order, page)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, songsResponse(list, page))
 }
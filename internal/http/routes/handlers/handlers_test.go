@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// stubService answers CreateSong only; other methods panic.
+type stubService struct {
+	Service
+}
+
+func (stubService) CreateSong(_ context.Context, input *entity.CreateSongInput) (*entity.Song, error) {
+	return &entity.Song{SongID: 7, ArtistID: 3, Group: input.Group, SongName: input.SongName, Version: 1}, nil
+}
+
+func TestCreateSongResponses(t *testing.T) {
+	h := NewHandler(stubService{}, *NewValidator())
+
+	tests := []struct {
+		name         string
+		handler      gin.HandlerFunc
+		wantStatus   int
+		wantLocation string
+		wantETag     string
+		wrapped      bool
+	}{
+		{name: "v1 wrapper", handler: h.CreateSong, wantStatus: http.StatusOK, wrapped: true},
+		{name: "v2 resource", handler: h.PostSong, wantStatus: http.StatusCreated, wantLocation: "/api/v2/songs/7", wantETag: `"1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"group":"Muse","song_name":"Uprising"}`))
+
+			tt.handler(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+
+			var song entity.Song
+			if tt.wrapped {
+				var body map[string]entity.Song
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode body: %v", err)
+				}
+				song = body["created data"]
+			} else if err := json.Unmarshal(w.Body.Bytes(), &song); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+
+			if song.SongID != 7 || song.SongName != "Uprising" {
+				t.Errorf("song = %+v, want song 7 Uprising", song)
+			}
+		})
+	}
+}
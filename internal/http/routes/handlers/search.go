@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler godoc
+// @Summary Поиск песен
+// @Description Полнотекстовый поиск по названиям песен, группам и текстам. Запрос в синтаксисе websearch: слова, "фразы", or, -исключения. Результаты упорядочены по релевантности, совпадения в тексте выделены тегом <b>, остальной текст фрагмента экранирован как HTML
+// @Tags songs
+// @Produce  json
+// @Param q query string true "Поисковый запрос"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Лимит элементов на странице" default(10)
+// @Success 200 {object} entity.SearchResponse
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 422 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /search [get]
+// @Router /api/v2/search [get]
+func (h *Handler) Search(c *gin.Context) {
+	var query entity.SearchQuery
+	var pagination entity.Pagination
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "Invalid query parameters"})
+
+		return
+	}
+
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorResponse{Error: "Invalid pagination parameters"})
+
+		return
+	}
+
+	if err := h.validator.Struct(query); err != nil {
+		errorResponse(c, invalid(err))
+
+		return
+	}
+
+	if err := h.validator.Struct(pagination); err != nil {
+		errorResponse(c, invalid(err))
+
+		return
+	}
+
+	results, totalItems, err := h.service.Search(c.Request.Context(), query.Q, pagination)
+	if err != nil {
+		errorResponse(c, err)
+
+		return
+	}
+
+	totalPages := totalItems / pagination.Limit
+	if totalItems%pagination.Limit != 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, entity.SearchResponse{
+		Data:       results,
+		Page:       pagination.Page,
+		TotalPages: totalPages,
+		TotalItems: totalItems,
+	})
+}
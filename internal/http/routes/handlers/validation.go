@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/go-playground/validator/v10"
+)
+
+// NewValidator returns a validator that reports fields by their JSON (or
+// query) names and understands merge patch fields.
+func NewValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+
+	// Absent and null patch members validate as nil, so "omitempty" skips
+	// them; validatePatch handles null and empty values.
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		n, ok := field.Interface().(entity.NullableString)
+		if !ok || !n.Set || n.Null {
+			return nil
+		}
+
+		return n.Value
+	}, entity.NullableString{})
+
+	// Dates are parsed while binding, so here they are plain values; the
+	// zero date means the field was absent.
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		var d entity.Date
+
+		switch value := field.Interface().(type) {
+		case entity.Date:
+			d = value
+		case entity.NullableDate:
+			if !value.Set || value.Null {
+				return nil
+			}
+			d = value.Value
+		}
+
+		if d.IsZero() {
+			return nil
+		}
+
+		return d.Time
+	}, entity.Date{}, entity.NullableDate{})
+
+	v.RegisterStructValidation(validatePatch, entity.UpdateSongInput{})
+	v.RegisterStructValidation(validateSongFilter, entity.SongFilterQuery{})
+
+	return v
+}
+
+// validatePatch rejects null and empty values for patch members backed by
+// NOT NULL columns; only text and link can be cleared.
+func validatePatch(sl validator.StructLevel) {
+	patch := sl.Current().Interface().(entity.UpdateSongInput)
+
+	required := []struct {
+		name  string
+		field entity.NullableString
+	}{
+		{"group", patch.Group},
+		{"song", patch.Song},
+	}
+
+	for _, r := range required {
+		switch {
+		case r.field.Set && r.field.Null:
+			sl.ReportError(r.field, r.name, r.name, "notnull", "")
+		case r.field.Set && r.field.Value == "":
+			sl.ReportError(r.field, r.name, r.name, "required", "")
+		}
+	}
+
+	if patch.ReleaseDate.Set && patch.ReleaseDate.Null {
+		sl.ReportError(patch.ReleaseDate, "releaseDate", "releaseDate", "notnull", "")
+	}
+}
+
+// validateSongFilter checks the parts of the filter grammar that field tags
+// cannot express: operator values, the artist id list, the date range and
+// the sort keys.
+func validateSongFilter(sl validator.StructLevel) {
+	query := sl.Current().Interface().(entity.SongFilterQuery)
+
+	names := []struct {
+		name  string
+		value string
+	}{
+		{"group", query.Group},
+		{"song", query.Song},
+	}
+
+	for _, n := range names {
+		if m := parseNameMatch(n.value, entity.MatchExact); m != nil && len(m.Value) > 255 {
+			sl.ReportError(m.Value, n.name, n.name, "max", "255")
+		}
+	}
+
+	ids, err := parseArtistIDs(query.ArtistID)
+	switch {
+	case err != nil:
+		sl.ReportError(query.ArtistID, "artist_id", "ArtistID", "artist_ids", "")
+	case len(ids) > maxArtistIDs:
+		sl.ReportError(ids, "artist_id", "ArtistID", "max", strconv.Itoa(maxArtistIDs))
+	}
+
+	if _, err := parseSort(query.Sort); err != nil {
+		sl.ReportError(query.Sort, "sort", "Sort", "sort", strings.Join(songSortFields, " "))
+	}
+
+	from, to := query.ReleaseDateFrom, query.ReleaseDateTo
+	if !from.IsZero() && !to.IsZero() && to.Before(from.Time) {
+		sl.ReportError(query.ReleaseDateTo, "release_date_to", "ReleaseDateTo", "gtefield", "release_date_from")
+	}
+}
+
+// fieldErrors converts validator output and binding errors into the
+// documented response shape.
+func fieldErrors(err error) []entity.FieldError {
+	var bindErrs bindErrors
+	if errors.As(err, &bindErrs) {
+		return bindErrs
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make([]entity.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, entity.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	return fields
+}
+
+// dateMessage describes the "date" rule, which is checked while binding.
+const dateMessage = "must be a date in YYYY-MM-DD or DD.MM.YYYY format"
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "notnull":
+		return "cannot be null"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+
+		return "must be at most " + fe.Param()
+	case "url":
+		return "must be a valid URL"
+	case "artist_ids":
+		return "must be a comma-separated list of positive integers"
+	case "sort":
+		return "must be a comma-separated list of distinct keys from: " + fe.Param() + ", each optionally prefixed with -"
+	case "gtefield":
+		return "must not be before " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+func TestValidatePatch(t *testing.T) {
+	v := NewValidator()
+
+	type fieldRule struct{ Field, Rule string }
+
+	tests := []struct {
+		name  string
+		patch entity.UpdateSongInput
+		want  []fieldRule
+	}{
+		{name: "empty patch"},
+		{
+			name: "nullable members may be cleared",
+			patch: entity.UpdateSongInput{
+				Text: entity.NullableString{Set: true, Null: true},
+				Link: entity.NullableString{Set: true, Null: true},
+			},
+		},
+		{
+			name: "values",
+			patch: entity.UpdateSongInput{
+				Group: entity.NullableString{Set: true, Value: "Muse"},
+				Song:  entity.NullableString{Set: true, Value: "Uprising"},
+				Link:  entity.NullableString{Set: true, Value: "https://example.com"},
+			},
+		},
+		{
+			name: "null for NOT NULL columns",
+			patch: entity.UpdateSongInput{
+				Group:       entity.NullableString{Set: true, Null: true},
+				Song:        entity.NullableString{Set: true, Null: true},
+				ReleaseDate: entity.NullableDate{Set: true, Null: true},
+			},
+			want: []fieldRule{{"group", "notnull"}, {"song", "notnull"}, {"releaseDate", "notnull"}},
+		},
+		{
+			name:  "empty name",
+			patch: entity.UpdateSongInput{Song: entity.NullableString{Set: true}},
+			want:  []fieldRule{{"song", "required"}},
+		},
+		{
+			name:  "bad link",
+			patch: entity.UpdateSongInput{Link: entity.NullableString{Set: true, Value: "not a url"}},
+			want:  []fieldRule{{"link", "url"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []fieldRule
+			for _, fe := range fieldErrors(v.Struct(tt.patch)) {
+				got = append(got, fieldRule{fe.Field, fe.Rule})
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
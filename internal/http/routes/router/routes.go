@@ -14,11 +14,24 @@ import (
 
 type Handler interface {
 	CreateSong(c *gin.Context)
+	PostSong(c *gin.Context)
 	GetSongByQuery(c *gin.Context)
 	DeleteSong(c *gin.Context)
 	UpdateFieldSong(c *gin.Context)
 	GetSongText(c *gin.Context)
+	GetSongVerse(c *gin.Context)
 	GetSongs(c *gin.Context)
+	CreateArtist(c *gin.Context)
+	GetArtist(c *gin.Context)
+	GetArtists(c *gin.Context)
+	UpdateArtist(c *gin.Context)
+	DeleteArtist(c *gin.Context)
+	GetSong(c *gin.Context)
+	UpdateSong(c *gin.Context)
+	PatchSong(c *gin.Context)
+	DeleteSongByID(c *gin.Context)
+	GetArtistSongs(c *gin.Context)
+	Search(c *gin.Context)
 }
 
 type Router struct {
@@ -52,20 +65,67 @@ func NewRouter(h Handler) *Router {
 	// swagger
 	r.GET("docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Добавление новой песни в формате JSON
-	r.POST("/create-song", h.CreateSong)
-	// Получение данных библиотеки с фильтрацией по всем полям и пагинацией
-	r.GET("/songs-with-filter", h.GetSongs)
-	// метод для получения информации о песне по названию группы и песни
-	r.GET("/info", h.GetSongByQuery)
-	// Получение текста песни и назвыания с пагинацией по куплетам по ID
-	r.GET("/song-text/:id/text", h.GetSongText)
-	// Удаление песни по названию группы и песни
-	r.DELETE("/delete-song", h.DeleteSong)
-	// Частичное изменение данных песни по названию группы и песни
-	r.PATCH("/update-song", h.UpdateFieldSong)
-	// // Изменение данных песни
-	// r.PUT("/update-song/:id", h.UpdateSong)
+	// v1: устаревшие маршруты, оставлены для совместимости
+	v1 := r.Group("/", deprecated("/api/v2"))
+	{
+		// Добавление новой песни в формате JSON
+		v1.POST("/create-song", h.CreateSong)
+		// Получение данных библиотеки с фильтрацией по всем полям и пагинацией
+		v1.GET("/songs-with-filter", h.GetSongs)
+		// метод для получения информации о песне по названию группы и песни
+		v1.GET("/info", h.GetSongByQuery)
+		// Получение текста песни и назвыания с пагинацией по куплетам по ID
+		v1.GET("/song-text/:id/text", h.GetSongText)
+		// Удаление песни по названию группы и песни
+		v1.DELETE("/delete-song", h.DeleteSong)
+		// Частичное изменение данных песни по названию группы и песни
+		v1.PATCH("/update-song", h.UpdateFieldSong)
+		// Изменение данных песни
+		v1.PUT("/update-song/:id", h.UpdateSong)
+
+		// Управление исполнителями
+		v1.POST("/artists", h.CreateArtist)
+		v1.GET("/artists", h.GetArtists)
+		v1.GET("/artists/:id", h.GetArtist)
+		v1.PUT("/artists/:id", h.UpdateArtist)
+		v1.DELETE("/artists/:id", h.DeleteArtist)
+	}
+
+	// Полнотекстовый поиск по песням; маршрут новый, поэтому не устаревший
+	r.GET("/search", h.Search)
+
+	// v2: ресурсы адресуются по ID
+	v2 := r.Group("/api/v2")
+	{
+		v2.GET("/songs", h.GetSongs)
+		v2.POST("/songs", h.PostSong)
+		v2.GET("/songs/:id", h.GetSong)
+		v2.PUT("/songs/:id", h.UpdateSong)
+		v2.PATCH("/songs/:id", h.PatchSong)
+		v2.DELETE("/songs/:id", h.DeleteSongByID)
+		v2.GET("/songs/:id/verses", h.GetSongText)
+		v2.GET("/songs/:id/verses/:index", h.GetSongVerse)
+
+		v2.POST("/artists", h.CreateArtist)
+		v2.GET("/artists", h.GetArtists)
+		v2.GET("/artists/:id", h.GetArtist)
+		v2.PUT("/artists/:id", h.UpdateArtist)
+		v2.DELETE("/artists/:id", h.DeleteArtist)
+		v2.GET("/artists/:id/songs", h.GetArtistSongs)
+
+		// Полнотекстовый поиск по названиям, группам и текстам песен
+		v2.GET("/search", h.Search)
+	}
 
 	return &Router{Router: r}
 }
+
+// deprecated marks legacy routes with the Deprecation header and points
+// clients to the successor API.
+func deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		c.Next()
+	}
+}
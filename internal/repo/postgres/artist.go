@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+// resolveArtist returns the id of the artist with the given group name and
+// inserts it when missing. When a concurrent caller inserts the same name
+// first, ON CONFLICT returns no row and the committed artist is read instead.
+func resolveArtist(ctx context.Context, q querier, group string) (int, error) {
+	var artistID int
+
+	err := q.QueryRow(ctx, `
+		INSERT INTO Artists(group_name) VALUES($1)
+		ON CONFLICT (group_name) DO NOTHING
+		RETURNING artist_id`, group).Scan(&artistID)
+	if err == nil {
+		return artistID, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("resolveArtist: %w", translateError(err))
+	}
+
+	err = q.QueryRow(ctx, "SELECT artist_id FROM Artists WHERE group_name = $1", group).Scan(&artistID)
+	if err != nil {
+		return 0, fmt.Errorf("resolveArtist: %w", translateError(err))
+	}
+
+	return artistID, nil
+}
+
+// EnsureArtist returns the artist with the given group name, creating it
+// when missing.
+func (s *Repository) EnsureArtist(ctx context.Context, group string) (*entity.Artist, error) {
+	const methodName = "EnsureArtist"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	artistID, err := resolveArtist(ctx, s.conn(ctx), group)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", methodName, err)
+	}
+
+	return &entity.Artist{ArtistID: artistID, GroupName: group}, nil
+}
+
+func (s *Repository) CreateArtist(ctx context.Context, input *entity.ArtistInput) (*entity.Artist, error) {
+	const methodName = "CreateArtist"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	artist := entity.Artist{GroupName: input.GroupName}
+	query := "INSERT INTO Artists(group_name) VALUES($1) RETURNING artist_id"
+
+	err := s.conn(ctx).QueryRow(ctx, query, input.GroupName).Scan(&artist.ArtistID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	return &artist, nil
+}
+
+func (s *Repository) GetArtistByID(ctx context.Context, id int) (*entity.Artist, error) {
+	const methodName = "GetArtistByID"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var artist entity.Artist
+	query := "SELECT artist_id, group_name FROM Artists WHERE artist_id = $1"
+
+	err := s.conn(ctx).QueryRow(ctx, query, id).Scan(&artist.ArtistID, &artist.GroupName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", methodName, ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	return &artist, nil
+}
+
+func (s *Repository) GetAllArtists(ctx context.Context, pagination entity.Pagination) ([]entity.Artist, int, error) {
+	const methodName = "GetAllArtists"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT artist_id, group_name
+		FROM Artists
+		ORDER BY group_name, artist_id
+		LIMIT $1 OFFSET $2`
+
+	batch := &pgx.Batch{}
+	batch.Queue("SELECT COUNT(*) FROM Artists")
+	batch.Queue(query, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+
+	results := s.conn(ctx).SendBatch(ctx, batch)
+	defer func() {
+		if err := results.Close(); err != nil {
+			log.Printf("%s: ошибка закрытия batch: %v", methodName, err)
+		}
+	}()
+
+	var total int
+
+	if err := results.QueryRow().Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+	defer rows.Close()
+
+	artists := make([]entity.Artist, 0, pagination.Limit)
+
+	for rows.Next() {
+		var artist entity.Artist
+		if err := rows.Scan(&artist.ArtistID, &artist.GroupName); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+		}
+		artists = append(artists, artist)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	return artists, total, nil
+}
+
+// UpdateArtist renames the artist. The group name is part of every song of
+// the artist, so the trigger from the 000007 migration bumps their versions
+// and their ETags change with it.
+func (s *Repository) UpdateArtist(ctx context.Context, id int, input *entity.ArtistInput) (*entity.Artist, error) {
+	const methodName = "UpdateArtist"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	artist := entity.Artist{GroupName: input.GroupName}
+	query := "UPDATE Artists SET group_name = $1 WHERE artist_id = $2 RETURNING artist_id"
+
+	err := s.conn(ctx).QueryRow(ctx, query, input.GroupName, id).Scan(&artist.ArtistID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", methodName, ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	return &artist, nil
+}
+
+func (s *Repository) DeleteArtist(ctx context.Context, id int) error {
+	const methodName = "DeleteArtist"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.conn(ctx).Exec(ctx, "DELETE FROM Artists WHERE artist_id = $1", id)
+	if err != nil {
+		// Удаление исполнителя, на которого ссылаются песни, - конфликт, а не
+		// ошибка во входных данных.
+		if err = translateError(err); errors.Is(err, ErrInvalidReference) {
+			return fmt.Errorf("%s: artist has songs: %w", methodName, ErrConflict)
+		}
+
+		return fmt.Errorf("%s: %w", methodName, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", methodName, ErrNotFound)
+	}
+
+	return nil
+}
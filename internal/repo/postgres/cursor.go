@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+// sortColumn describes a sort key: the column it orders by and the type its
+// cursor value is cast to.
+type sortColumn struct {
+	column string
+	cast   string
+}
+
+// songSortColumns maps the sort keys accepted by GetAllSongs to columns.
+var songSortColumns = map[string]sortColumn{
+	"song_name":    {"s.song_name", "text"},
+	"release_date": {"s.release_date", "date"},
+	"song_id":      {"s.song_id", "int"},
+	"group":        {"a.group_name", "text"},
+	"artist_id":    {"s.artist_id", "int"},
+}
+
+type sortTerm struct {
+	entity.SortField
+	sortColumn
+}
+
+// sortTerms resolves whitelisted keys only and ends the order with song_id,
+// so pages are stable and a cursor identifies exactly one row.
+func sortTerms(order []entity.SortField) ([]sortTerm, error) {
+	terms := make([]sortTerm, 0, len(order)+1)
+	hasID := false
+
+	for _, field := range order {
+		column, ok := songSortColumns[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown sort key %q", ErrInvalidInput, field.Name)
+		}
+
+		terms = append(terms, sortTerm{field, column})
+		hasID = hasID || field.Name == "song_id"
+	}
+
+	if !hasID {
+		terms = append(terms, sortTerm{entity.SortField{Name: "song_id"}, songSortColumns["song_id"]})
+	}
+
+	return terms, nil
+}
+
+func orderBy(terms []sortTerm) string {
+	columns := make([]string, len(terms))
+	for i, t := range terms {
+		columns[i] = t.column
+		if t.Desc {
+			columns[i] += " DESC"
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// sortSignature renders the order back in the sort parameter syntax.
+func sortSignature(terms []sortTerm) string {
+	keys := make([]string, len(terms))
+	for i, t := range terms {
+		keys[i] = t.Name
+		if t.Desc {
+			keys[i] = "-" + keys[i]
+		}
+	}
+
+	return strings.Join(keys, ",")
+}
+
+// songCursor is the position after the last row of a page: the values of
+// its sort keys. Sort records the order the cursor was issued for, so it is
+// not reused with another one.
+type songCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func encodeCursor(terms []sortTerm, last entity.Song) string {
+	values := make([]string, len(terms))
+	for i, t := range terms {
+		values[i] = sortValue(last, t.Name)
+	}
+
+	// Маршалинг структуры из строк не может завершиться ошибкой.
+	data, _ := json.Marshal(songCursor{Sort: sortSignature(terms), Values: values})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string, terms []sortTerm) (*songCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+
+	var cursor songCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+
+	if cursor.Sort != sortSignature(terms) || len(cursor.Values) != len(terms) {
+		return nil, fmt.Errorf("%w: issued for a different sort", ErrInvalidCursor)
+	}
+
+	return &cursor, nil
+}
+
+func sortValue(song entity.Song, key string) string {
+	switch key {
+	case "song_name":
+		return song.SongName
+	case "release_date":
+		return song.ReleaseDate.String()
+	case "group":
+		return song.Group
+	case "artist_id":
+		return strconv.Itoa(song.ArtistID)
+	default:
+		return strconv.Itoa(song.SongID)
+	}
+}
+
+// afterCursor builds the keyset condition selecting rows that follow the
+// cursor in the given order:
+//
+//	k1 > v1 OR (k1 = v1 AND k2 > v2) OR ...
+//
+// with "<" for descending keys. The values are appended to args.
+func afterCursor(terms []sortTerm, cursor *songCursor, args []any) (string, []any) {
+	placeholders := make([]string, len(terms))
+	for i, t := range terms {
+		args = append(args, cursor.Values[i])
+		placeholders[i] = fmt.Sprintf("$%d::%s", len(args), t.cast)
+	}
+
+	alternatives := make([]string, len(terms))
+	for i, t := range terms {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, terms[j].column+" = "+placeholders[j])
+		}
+
+		op := " > "
+		if t.Desc {
+			op = " < "
+		}
+		parts = append(parts, t.column+op+placeholders[i])
+
+		alternatives[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
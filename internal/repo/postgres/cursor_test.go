@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+func mustSortTerms(t *testing.T, order ...entity.SortField) []sortTerm {
+	t.Helper()
+
+	terms, err := sortTerms(order)
+	if err != nil {
+		t.Fatalf("sortTerms(%v): %v", order, err)
+	}
+
+	return terms
+}
+
+func TestSortTerms(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     []entity.SortField
+		wantOrder string
+		wantSig   string
+	}{
+		{
+			name:      "song_id tiebreaker is appended",
+			order:     []entity.SortField{{Name: "song_name"}, {Name: "release_date", Desc: true}},
+			wantOrder: "s.song_name, s.release_date DESC, s.song_id",
+			wantSig:   "song_name,-release_date,song_id",
+		},
+		{
+			name:      "explicit song_id is kept in place",
+			order:     []entity.SortField{{Name: "song_id", Desc: true}, {Name: "group"}},
+			wantOrder: "s.song_id DESC, a.group_name",
+			wantSig:   "-song_id,group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms := mustSortTerms(t, tt.order...)
+
+			if got := orderBy(terms); got != tt.wantOrder {
+				t.Errorf("orderBy = %q, want %q", got, tt.wantOrder)
+			}
+
+			if got := sortSignature(terms); got != tt.wantSig {
+				t.Errorf("sortSignature = %q, want %q", got, tt.wantSig)
+			}
+		})
+	}
+}
+
+func TestSortTermsUnknownKey(t *testing.T) {
+	_, err := sortTerms([]entity.SortField{{Name: "song_text"}})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("sortTerms error = %v, want %v", err, ErrInvalidInput)
+	}
+}
+
+func TestAfterCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     []entity.SortField
+		values    []string
+		args      []any
+		wantWhere string
+		wantArgs  []any
+	}{
+		{
+			name:      "single key",
+			order:     []entity.SortField{{Name: "song_id"}},
+			values:    []string{"7"},
+			wantWhere: "((s.song_id > $1::int))",
+			wantArgs:  []any{"7"},
+		},
+		{
+			name:   "mixed directions after filter args",
+			order:  []entity.SortField{{Name: "release_date", Desc: true}, {Name: "song_name"}},
+			values: []string{"2006-07-16", "Uprising", "7"},
+			args:   []any{"Muse", 3},
+			wantWhere: "((s.release_date < $3::date)" +
+				" OR (s.release_date = $3::date AND s.song_name > $4::text)" +
+				" OR (s.release_date = $3::date AND s.song_name = $4::text AND s.song_id > $5::int))",
+			wantArgs: []any{"Muse", 3, "2006-07-16", "Uprising", "7"},
+		},
+		{
+			name:   "descending tiebreaker",
+			order:  []entity.SortField{{Name: "group"}, {Name: "song_id", Desc: true}},
+			values: []string{"Muse", "7"},
+			wantWhere: "((a.group_name > $1::text)" +
+				" OR (a.group_name = $1::text AND s.song_id < $2::int))",
+			wantArgs: []any{"Muse", "7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms := mustSortTerms(t, tt.order...)
+
+			where, args := afterCursor(terms, &songCursor{Values: tt.values}, tt.args)
+			if where != tt.wantWhere {
+				t.Errorf("afterCursor where =\n%s\nwant\n%s", where, tt.wantWhere)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("afterCursor args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	terms := mustSortTerms(t,
+		entity.SortField{Name: "group"},
+		entity.SortField{Name: "release_date", Desc: true},
+		entity.SortField{Name: "artist_id"},
+	)
+
+	last := entity.Song{
+		SongID:      42,
+		ArtistID:    3,
+		Group:       "Muse",
+		SongName:    "Supermassive Black Hole",
+		ReleaseDate: entity.NewDate(time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC)),
+	}
+
+	cursor, err := decodeCursor(encodeCursor(terms, last), terms)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+
+	want := songCursor{
+		Sort:   "group,-release_date,artist_id,song_id",
+		Values: []string{"Muse", "2006-07-16", "3", "42"},
+	}
+	if !reflect.DeepEqual(*cursor, want) {
+		t.Errorf("decodeCursor = %+v, want %+v", *cursor, want)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	issued := mustSortTerms(t, entity.SortField{Name: "song_name"})
+	raw := encodeCursor(issued, entity.Song{SongID: 1, SongName: "Uprising"})
+
+	tests := []struct {
+		name  string
+		raw   string
+		terms []sortTerm
+	}{
+		{name: "different sort", raw: raw, terms: mustSortTerms(t, entity.SortField{Name: "song_name", Desc: true})},
+		{name: "extra sort key", raw: raw, terms: mustSortTerms(t, entity.SortField{Name: "song_name"}, entity.SortField{Name: "group"})},
+		{name: "not base64", raw: "%%%", terms: issued},
+		{name: "not json", raw: "bm90IGpzb24", terms: issued},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.raw, tt.terms); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotFound         = errors.New("record not found")
+	ErrConflict         = errors.New("record conflicts with existing data")
+	ErrInvalidReference = errors.New("referenced record not found")
+	ErrVersionMismatch  = errors.New("record version mismatch")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrTimeout          = errors.New("query timed out")
+)
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	uniqueViolation         = "23505"
+	foreignKeyViolation     = "23503"
+	notNullViolation        = "23502"
+	checkViolation          = "23514"
+	stringDataTruncation    = "22001"
+	invalidDatetimeFormat   = "22007"
+	datetimeFieldOverflow   = "22008"
+	invalidTextRepresention = "22P02"
+	queryCanceled           = "57014"
+)
+
+// translateError maps driver errors to the repository sentinels, keeping
+// the original message for logs. Unknown errors are returned unchanged.
+func translateError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolation:
+		return fmt.Errorf("%w: %s", ErrConflict, pgErr.Message)
+	case foreignKeyViolation:
+		return fmt.Errorf("%w: %s", ErrInvalidReference, pgErr.Message)
+	case notNullViolation, checkViolation, stringDataTruncation,
+		invalidDatetimeFormat, datetimeFieldOverflow, invalidTextRepresention:
+		return fmt.Errorf("%w: %s", ErrInvalidInput, pgErr.Message)
+	case queryCanceled:
+		// Сервер отменил запрос по statement_timeout или по отмене контекста.
+		return fmt.Errorf("%w: %s", ErrTimeout, pgErr.Message)
+	default:
+		return err
+	}
+}
@@ -1,309 +1,494 @@
 package postgres
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/DobryySoul/test-task/config"
 	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository struct {
-	db *sql.DB
+	pool         *pgxpool.Pool
+	queryTimeout time.Duration
 }
 
-func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+func NewRepository(pool *pgxpool.Pool, cfg config.PG) *Repository {
+	return &Repository{pool: pool, queryTimeout: cfg.QueryTimeout}
 }
 
-var ErrNotFound = errors.New("record not found")
+// withTimeout bounds a repository call, including every statement of its
+// transaction, by the configured query timeout. Zero disables the limit.
+func (s *Repository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+// songColumns is the select list scanSong expects, with s aliasing Songs and
+// a aliasing Artists.
+const songColumns = "s.song_id, s.song_name, s.release_date, s.song_text, s.link, s.artist_id, a.group_name, s.version"
+
+// songReturning is songColumns for UPDATE ... RETURNING on Songs.
+const songReturning = `song_id, song_name, release_date, song_text, link, artist_id,
+                       (SELECT group_name FROM Artists WHERE artist_id = Songs.artist_id), version`
+
+// scanSong reads a row in songColumns order followed by extra columns. NULL
+// text or link stay nil.
+func scanSong(row pgx.Row, extra ...any) (*entity.Song, error) {
+	var song entity.Song
+
+	dest := append([]any{
+		&song.SongID,
+		&song.SongName,
+		&song.ReleaseDate,
+		&song.SongText,
+		&song.Link,
+		&song.ArtistID,
+		&song.Group,
+		&song.Version,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	return &song, nil
+}
+
+// CreateSong stores the song under song.ArtistID. Use EnsureArtist in the
+// same WithTx to create the artist and the song atomically.
+func (s *Repository) CreateSong(ctx context.Context, song *entity.Song) error {
+	const methodName = "CreateSong"
 
-func (s *Repository) CreateSong(song *entity.CreateSongInput) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
 	query := `
 		INSERT INTO Songs(song_name, release_date, song_text, link, artist_id)
 		VALUES($1, $2, $3, $4, $5)
+		RETURNING song_id, version
 	`
 
-	_, err := s.db.Exec(query, song.SongName, song.ReleaseDate, song.SongText, song.Link, song.ArtistID)
+	err := s.conn(ctx).QueryRow(ctx, query, song.SongName, song.ReleaseDate, song.SongText, song.Link, song.ArtistID).Scan(&song.SongID, &song.Version)
 	if err != nil {
-		return fmt.Errorf("возникла ошибка в добавлении песни: %w", err)
+		return fmt.Errorf("%s: возникла ошибка в добавлении песни: %w", methodName, translateError(err))
 	}
 
 	return nil
 }
 
-func (s *Repository) GetByGroupAndSongName(group, songName string) (*entity.Song, error) {
-	const methodName = "GetByGroupAndSongName"
+func (s *Repository) GetByGroupAndSongName(ctx context.Context, group, songName string) (*entity.Song, error) {
+	return s.getByGroupAndSongName(ctx, "GetByGroupAndSongName", group, songName, "")
+}
 
-	var song entity.Song
-	query := `SELECT s.song_id, s.song_name, s.release_date, s.song_text, s.link, s.artist_id
+// GetByGroupAndSongNameForUpdate also locks the song row until the end of
+// the transaction, so it must be called inside WithTx.
+func (s *Repository) GetByGroupAndSongNameForUpdate(ctx context.Context, group, songName string) (*entity.Song, error) {
+	return s.getByGroupAndSongName(ctx, "GetByGroupAndSongNameForUpdate", group, songName, " FOR UPDATE OF s")
+}
+
+func (s *Repository) getByGroupAndSongName(ctx context.Context, methodName, group, songName, lock string) (*entity.Song, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + songColumns + `
 			  FROM Songs s
 			  JOIN Artists a ON s.artist_id = a.artist_id
-			  WHERE a.group_name = $1 AND s.song_name = $2`
+			  WHERE a.group_name = $1 AND s.song_name = $2` + lock
 
-	stmt, err := s.db.Prepare(query)
+	song, err := scanSong(s.conn(ctx).QueryRow(ctx, query, group, songName))
 	if err != nil {
-		return nil, fmt.Errorf("%s: ошибка в подготовке stmt: %w", methodName, err)
-	}
-	defer func() {
-		if err := stmt.Close(); err != nil {
-			log.Printf("%s: ошибка закрытия stmt: %v", methodName, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", methodName, ErrNotFound)
 		}
-	}()
 
-	err = stmt.QueryRow(group, songName).Scan(
-		&song.SongID,
-		&song.SongName,
-		&song.ReleaseDate,
-		&song.SongText,
-		&song.Link,
-		&song.ArtistID,
-	)
+		return nil, fmt.Errorf("%s: ошибка при выполнении запроса: %w", methodName, translateError(err))
+	}
+
+	return song, nil
+}
+
+func (s *Repository) GetByID(ctx context.Context, id int) (*entity.Song, error) {
+	const methodName = "GetByID"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + songColumns + `
+			  FROM Songs s
+			  JOIN Artists a ON s.artist_id = a.artist_id
+			  WHERE s.song_id = $1`
+
+	song, err := scanSong(s.conn(ctx).QueryRow(ctx, query, id))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", methodName, ErrNotFound)
 		}
 
-		return nil, fmt.Errorf("%s: ошибка при выполнении запроса: %w", methodName, err)
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
 	}
 
-	return &song, nil
+	return song, nil
 }
 
-func (s *Repository) GetByID(id int) (*entity.Song, error) {
-	const methodName = "GetByID"
+// UpdateFieldSong applies a merge patch to the song: only the supplied
+// fields end up in the SET clause, null clears nullable columns, and a new
+// group moves the song to that artist, creating it when needed. The artist
+// and the song change in one transaction, joining the caller's one if any.
+func (s *Repository) UpdateFieldSong(ctx context.Context, id int, patch *entity.UpdateSongInput, versions []int) (*entity.Song, error) {
+	const methodName = "UpdateFieldSong"
 
-	var song entity.Song
-	query := "SELECT song_id, song_name, release_date, song_text, link FROM Songs WHERE song_id = $1"
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
-	stmt, err := s.db.Prepare(query)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", methodName, err)
-	}
-	defer func() {
-		if closeErr := stmt.Close(); closeErr != nil {
-			log.Printf("%s: ошибка закрытия stmt: %v", methodName, closeErr)
+	var song *entity.Song
+
+	err := s.WithTx(ctx, func(ctx context.Context) error {
+		var artistID int
+
+		if patch.Group.Set {
+			var err error
+
+			artistID, err = resolveArtist(ctx, s.conn(ctx), patch.Group.Value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", methodName, translateError(err))
+			}
 		}
-	}()
 
-	err = stmt.QueryRow(id).Scan(
-		&song.SongID,
-		&song.SongName,
-		&song.ReleaseDate,
-		&song.SongText,
-		&song.Link,
-	)
+		setClauses, args := patchSet(patch, artistID)
+
+		args = append(args, id, versions)
+		query := fmt.Sprintf(`UPDATE Songs
+             SET %s
+             WHERE song_id = $%d AND ($%d::int[] IS NULL OR version = ANY($%d))
+             RETURNING %s`,
+			strings.Join(setClauses, ", "), len(args)-1, len(args), len(args), songReturning)
+
+		var err error
+
+		song, err = scanSong(s.conn(ctx).QueryRow(ctx, query, args...))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%s: %w", methodName, missingSongError(ctx, s.conn(ctx), id))
+			}
 
+			return fmt.Errorf("%s: ошибка при выполнении запроса: %w", methodName, translateError(err))
+		}
+
+		return nil
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%s: %w", methodName, ErrNotFound)
+		return nil, err
+	}
+
+	return song, nil
+}
+
+// patchSet builds the SET clause of a merge patch: only the supplied members
+// become assignments, null ones assign NULL, and a new group assigns
+// artistID. A non-empty patch also bumps the version; an empty one is a
+// no-op assignment, so the UPDATE still reports whether the song exists.
+func patchSet(patch *entity.UpdateSongInput, artistID int) ([]string, []any) {
+	var setClauses []string
+	var args []any
+
+	add := func(column string, value any) {
+		args = append(args, value)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	set := func(column string, field entity.NullableString) {
+		if !field.Set {
+			return
 		}
 
-		return nil, fmt.Errorf("%s: %w", methodName, err)
+		// nil уходит как NULL.
+		var value *string
+		if !field.Null {
+			value = &field.Value
+		}
+
+		add(column, value)
 	}
 
-	return &song, nil
+	set("song_name", patch.Song)
+	set("song_text", patch.Text)
+	set("link", patch.Link)
+
+	if patch.ReleaseDate.Set {
+		add("release_date", patch.ReleaseDate.Value)
+	}
+
+	if patch.Group.Set {
+		add("artist_id", artistID)
+	}
+
+	if len(setClauses) == 0 {
+		// Пустой патч ничего не меняет, но песня должна существовать.
+		return []string{"song_id = song_id"}, nil
+	}
+
+	return append(setClauses, "version = version + 1"), args
 }
 
-func (s *Repository) UpdateFieldSong(updateField *entity.UpdateSongInput, song *entity.Song) error {
-	const methodName = "UpdateFieldSong"
+// UpdateSong replaces every column of the song, including its artist.
+func (s *Repository) UpdateSong(ctx context.Context, id int, input *entity.SongInput, versions []int) (*entity.Song, error) {
+	const methodName = "UpdateSong"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
 	query := `UPDATE Songs
              SET song_name = $1,
                  release_date = $2,
                  song_text = $3,
-                 link = $4
-             WHERE song_id = $5`
-
-	stmt, err := s.db.Prepare(query)
+                 link = $4,
+                 artist_id = $5,
+                 version = version + 1
+             WHERE song_id = $6 AND ($7::int[] IS NULL OR version = ANY($7))
+             RETURNING ` + songReturning
+
+	song, err := scanSong(s.conn(ctx).QueryRow(
+		ctx,
+		query,
+		input.SongName,
+		input.ReleaseDate,
+		input.SongText,
+		input.Link,
+		input.ArtistID,
+		id,
+		versions,
+	))
 	if err != nil {
-		return fmt.Errorf("%s: ошибка в подготовке stmt: %w", methodName, err)
-	}
-	defer func() {
-		if closeErr := stmt.Close(); closeErr != nil {
-			log.Printf("%s: ошибка закрытия stmt: %v", methodName, closeErr)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", methodName, missingSongError(ctx, s.conn(ctx), id))
 		}
-	}()
 
-	switch {
-	case updateField.Song != "":
-		song.SongName = updateField.Song
-	case updateField.ReleaseDate != "":
-		song.ReleaseDate = updateField.ReleaseDate
-	case updateField.Text != "":
-		song.SongText = updateField.Text
-	case updateField.Link != "":
-		song.Link = updateField.Link
-	}
-
-	_, err = stmt.Exec(
-		song.SongName,
-		song.ReleaseDate,
-		song.SongText,
-		song.Link,
-		song.SongID,
-	)
-
-	if err != nil {
-		return fmt.Errorf("%s: ошибка при выполнении запроса: %w", methodName, err)
+		return nil, fmt.Errorf("%s: ошибка выполнения: %w", methodName, translateError(err))
 	}
 
-	return nil
+	return song, nil
 }
 
-// func (s *Repository) UpdateSong(song *entity.Song, ID int) error {
-// 	const methodName = "UpdateSong"
-
-// 	query := `UPDATE songs
-//              SET group_name = $1,
-//                  song_name = $2,
-//                  release_date = $3,
-//                  text = $4,
-//                  link = $5
-//              WHERE id = $6`
-
-// 	stmt, err := s.db.Prepare(query)
-// 	if err != nil {
-// 		return fmt.Errorf("%s: ошибка подготовки: %w", methodName, err)
-// 	}
-// 	defer func() {
-// 		if closeErr := stmt.Close(); closeErr != nil {
-// 			log.Printf("%s: ошибка закрытия stmt: %v", methodName, closeErr)
-// 		}
-// 	}()
-
-// 	song.ID = ID
-
-// 	_, err = stmt.Exec(
-// 		song.Group,
-// 		song.Song,
-// 		song.ReleaseDate,
-// 		song.Text,
-// 		song.Link,
-// 		song.ID,
-// 	)
-// 	if err != nil {
-// 		return fmt.Errorf("%s: ошибка выполнения: %w", methodName, err)
-// 	}
-
-// 	return nil
-// }
-
-func (s *Repository) Delete(id int) error {
+func (s *Repository) Delete(ctx context.Context, id int, versions []int) error {
 	const methodName = "Delete"
 
-	query := "DELETE FROM Songs WHERE song_id = $1"
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := "DELETE FROM Songs WHERE song_id = $1 AND ($2::int[] IS NULL OR version = ANY($2))"
 
-	stmt, err := s.db.Prepare(query)
+	tag, err := s.conn(ctx).Exec(ctx, query, id, versions)
 	if err != nil {
-		return fmt.Errorf("%s: %w", methodName, err)
+		return fmt.Errorf("%s: %w", methodName, translateError(err))
 	}
-	defer func() {
-		if closeErr := stmt.Close(); closeErr != nil {
-			log.Printf("%s: ошибка закрытия stmt: %v", methodName, closeErr)
-		}
-	}()
 
-	_, err = stmt.Exec(id)
-	if err != nil {
-		return fmt.Errorf("%s: %w", methodName, err)
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", methodName, missingSongError(ctx, s.conn(ctx), id))
 	}
 
 	return nil
 }
 
-func (s *Repository) GetAllSongs(filter entity.SongFilter, pagination entity.Pagination) ([]entity.Song, int, error) {
+// missingSongError explains why a conditional write matched no rows: the
+// song is either gone or was changed since the caller read its version.
+func missingSongError(ctx context.Context, q querier, id int) error {
+	var exists bool
+
+	err := q.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM Songs WHERE song_id = $1)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrVersionMismatch
+	}
+
+	return ErrNotFound
+}
+
+// GetAllSongs returns one page of the filtered song list. With a cursor the
+// page starts after the row it points to (keyset pagination); otherwise it
+// is selected by number. One extra row is read to tell whether a next page
+// exists.
+func (s *Repository) GetAllSongs(ctx context.Context, filter entity.SongFilter, order []entity.SortField, page entity.SongPage) (*entity.SongList, error) {
 	const methodName = "GetAll"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	terms, err := sortTerms(order)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", methodName, err)
+	}
+
+	var cursor *songCursor
+	if page.Cursor != "" {
+		if cursor, err = decodeCursor(page.Cursor, terms); err != nil {
+			return nil, fmt.Errorf("%s: %w", methodName, err)
+		}
+	}
+
 	var whereClauses []string
-	var args []interface{}
-	paramIdx := 1
-
-	buildCondition := func(filterValue *string, column string, exactMatch bool) {
-		if filterValue != nil && *filterValue != "" {
-			clause := ""
-			if exactMatch {
-				clause = fmt.Sprintf("%s = $%d", column, paramIdx)
-			} else {
-				clause = fmt.Sprintf("%s ILIKE $%d", column, paramIdx)
-				*filterValue = "%" + *filterValue + "%"
-			}
-			whereClauses = append(whereClauses, clause)
-			args = append(args, *filterValue)
-			paramIdx++
+	var args []any
+
+	// add appends a condition whose single placeholder is written as $%d.
+	add := func(format string, arg any) {
+		args = append(args, arg)
+		whereClauses = append(whereClauses, fmt.Sprintf(format, len(args)))
+	}
+
+	matchName := func(match *entity.NameMatch, column string) {
+		if match == nil || match.Value == "" {
+			return
+		}
+
+		switch match.Op {
+		case entity.MatchPrefix:
+			add(column+" ILIKE $%d", escapeLike(match.Value)+"%")
+		case entity.MatchContains:
+			add(column+" ILIKE $%d", "%"+escapeLike(match.Value)+"%")
+		default:
+			add(column+" = $%d", match.Value)
+		}
+	}
+
+	matchName(filter.Group, "a.group_name")
+	matchName(filter.Song, "s.song_name")
+	matchName(filter.Text, "s.song_text")
+
+	if filter.ReleaseDate != nil {
+		add("s.release_date = $%d", *filter.ReleaseDate)
+	}
+
+	if filter.ReleaseDateFrom != nil {
+		add("s.release_date >= $%d", *filter.ReleaseDateFrom)
+	}
+
+	if filter.ReleaseDateTo != nil {
+		add("s.release_date <= $%d", *filter.ReleaseDateTo)
+	}
+
+	if filter.Link != nil {
+		add("s.link = $%d", *filter.Link)
+	}
+
+	if filter.HasLink != nil {
+		if *filter.HasLink {
+			whereClauses = append(whereClauses, "COALESCE(s.link, '') <> ''")
+		} else {
+			whereClauses = append(whereClauses, "COALESCE(s.link, '') = ''")
 		}
 	}
 
-	buildCondition(filter.Song, "song_name", true)
-	buildCondition(filter.ReleaseDate, "release_date", true)
-	buildCondition(filter.Text, "song_text", false)
-	buildCondition(filter.Link, "link", true)
+	if len(filter.ArtistIDs) > 0 {
+		placeholders := make([]string, len(filter.ArtistIDs))
+		for i, id := range filter.ArtistIDs {
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+
+		whereClauses = append(whereClauses, "s.artist_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	from := " FROM Songs s JOIN Artists a ON s.artist_id = a.artist_id"
 
 	where := ""
 	if len(whereClauses) > 0 {
 		where = " WHERE " + strings.Join(whereClauses, " AND ")
 	}
 
-	countQuery := "SELECT COUNT(*) FROM Songs" + where
+	// Подсчет и страница уходят на сервер одним батчем.
+	batch := &pgx.Batch{}
+
+	if page.CountTotal() {
+		batch.Queue("SELECT COUNT(*)"+from+where, args...)
+	}
 
-	var total int
+	offset := 0
+	if cursor != nil {
+		var keyset string
+		keyset, args = afterCursor(terms, cursor, args)
 
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("%s: %w", methodName, err)
+		if where == "" {
+			where = " WHERE " + keyset
+		} else {
+			where += " AND " + keyset
+		}
+	} else {
+		offset = (page.Page - 1) * page.Limit
 	}
 
 	mainQuery := fmt.Sprintf(`
-        SELECT
-            song_id,
-            song_name,
-            release_date,
-            song_text,
-            link,
-			artist_id
-        FROM songs
+        SELECT %s
         %s
-		ORDER BY song_name, release_date, song_text, link, artist_id
+        %s
+		ORDER BY %s
         LIMIT $%d OFFSET $%d`,
-		where, paramIdx, paramIdx+1)
+		songColumns, from, where, orderBy(terms), len(args)+1, len(args)+2)
 
-	args = append(args, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+	args = append(args, page.Limit+1, offset)
+	batch.Queue(mainQuery, args...)
 
-	rows, err := s.db.Query(mainQuery, args...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("%s: %w", methodName, err)
-	}
+	results := s.conn(ctx).SendBatch(ctx, batch)
 	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Printf("%s: ошибка закрытия rows: %v", methodName, err)
+		if err := results.Close(); err != nil {
+			log.Printf("%s: ошибка закрытия batch: %v", methodName, err)
 		}
 	}()
 
-	var songs []entity.Song
+	var list entity.SongList
+
+	if page.CountTotal() {
+		var total int
+
+		if err := results.QueryRow().Scan(&total); err != nil {
+			return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+		}
+
+		list.Total = &total
+	}
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+	defer rows.Close()
+
+	songs := make([]entity.Song, 0, page.Limit+1)
 
 	for rows.Next() {
-		var song entity.Song
-		err := rows.Scan(
-			&song.SongID,
-			&song.SongName,
-			&song.ReleaseDate,
-			&song.SongText,
-			&song.Link,
-			&song.ArtistID,
-		)
+		song, err := scanSong(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("%s: %w", methodName, err)
+			return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
 		}
-		songs = append(songs, song)
+		songs = append(songs, *song)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("%s: %w", methodName, err)
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
 	}
 
-	return songs, total, nil
+	if len(songs) > page.Limit {
+		songs = songs[:page.Limit]
+		list.NextCursor = encodeCursor(terms, songs[len(songs)-1])
+	}
+
+	list.Songs = songs
+
+	return &list, nil
+}
+
+// escapeLike escapes the LIKE wildcards so user input matches literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
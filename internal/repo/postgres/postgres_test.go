@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+func TestPatchSet(t *testing.T) {
+	text, name := "Ooh baby", "Uprising"
+	date := entity.NewDate(time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name        string
+		patch       entity.UpdateSongInput
+		artistID    int
+		wantClauses []string
+		wantArgs    []any
+	}{
+		{
+			name:        "empty patch",
+			wantClauses: []string{"song_id = song_id"},
+		},
+		{
+			name:        "value",
+			patch:       entity.UpdateSongInput{Text: entity.NullableString{Set: true, Value: text}},
+			wantClauses: []string{"song_text = $1", "version = version + 1"},
+			wantArgs:    []any{&text},
+		},
+		{
+			name:        "null clears the column",
+			patch:       entity.UpdateSongInput{Link: entity.NullableString{Set: true, Null: true}},
+			wantClauses: []string{"link = $1", "version = version + 1"},
+			wantArgs:    []any{(*string)(nil)},
+		},
+		{
+			name: "absent members are skipped",
+			patch: entity.UpdateSongInput{
+				Song:        entity.NullableString{Value: "ignored"},
+				ReleaseDate: entity.NullableDate{Set: true, Value: date},
+			},
+			wantClauses: []string{"release_date = $1", "version = version + 1"},
+			wantArgs:    []any{date},
+		},
+		{
+			name: "every member",
+			patch: entity.UpdateSongInput{
+				Group:       entity.NullableString{Set: true, Value: "Muse"},
+				Song:        entity.NullableString{Set: true, Value: "Uprising"},
+				ReleaseDate: entity.NullableDate{Set: true, Value: date},
+				Text:        entity.NullableString{Set: true, Value: text},
+				Link:        entity.NullableString{Set: true, Null: true},
+			},
+			artistID: 3,
+			wantClauses: []string{
+				"song_name = $1", "song_text = $2", "link = $3", "release_date = $4", "artist_id = $5",
+				"version = version + 1",
+			},
+			wantArgs: []any{&name, &text, (*string)(nil), date, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clauses, args := patchSet(&tt.patch, tt.artistID)
+
+			if !reflect.DeepEqual(clauses, tt.wantClauses) {
+				t.Errorf("clauses = %q, want %q", clauses, tt.wantClauses)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+// headlineOptions keeps snippets short and marks matches with <b>.
+const headlineOptions = "StartSel=<b>, StopSel=</b>, MaxWords=25, MinWords=10, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+// escapedSongText is the song text with &, < and > replaced by HTML
+// entities. Lyrics come from the music info API and from clients, so they
+// are escaped before ts_headline adds its <b> marks, and a snippet never
+// carries markup other than those.
+const escapedSongText = `replace(replace(replace(COALESCE(s.song_text, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// Search matches the websearch query against search_vector, which the
+// triggers from the 000005 migration keep in sync with song names, group
+// names and lyrics, and orders the matches by ts_rank.
+func (s *Repository) Search(ctx context.Context, query string, pagination entity.Pagination) ([]entity.SearchResult, int, error) {
+	const methodName = "Search"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	searchQuery := `
+		SELECT ` + songColumns + `,
+			ts_rank(s.search_vector, q) AS rank,
+			ts_headline('simple', ` + escapedSongText + `, q, $2)
+		FROM Songs s
+		JOIN Artists a ON s.artist_id = a.artist_id,
+		     websearch_to_tsquery('simple', $1) q
+		WHERE s.search_vector @@ q
+		ORDER BY rank DESC, s.song_id
+		LIMIT $3 OFFSET $4`
+
+	batch := &pgx.Batch{}
+	batch.Queue("SELECT COUNT(*) FROM Songs WHERE search_vector @@ websearch_to_tsquery('simple', $1)", query)
+	batch.Queue(searchQuery, query, headlineOptions, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+
+	batchResults := s.conn(ctx).SendBatch(ctx, batch)
+	defer func() {
+		if err := batchResults.Close(); err != nil {
+			log.Printf("%s: ошибка закрытия batch: %v", methodName, err)
+		}
+	}()
+
+	var total int
+
+	if err := batchResults.QueryRow().Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	rows, err := batchResults.Query()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+	defer rows.Close()
+
+	results := make([]entity.SearchResult, 0, pagination.Limit)
+
+	for rows.Next() {
+		var r entity.SearchResult
+
+		song, err := scanSong(rows, &r.Rank, &r.Snippet)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+		}
+		r.Song = *song
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	return results, total, nil
+}
+
+// SuggestSongs ranks songs by trigram similarity of the group and song name
+// to the given ones. Candidates come from a UNION of two branches, artists
+// matching the group and songs matching the name, so that each % lookup is
+// served by its own pg_trgm index; an OR across the join would make Postgres
+// filter the joined rows instead. pg_trgm.similarity_threshold decides what
+// matches.
+func (s *Repository) SuggestSongs(ctx context.Context, group, songName string, limit int) ([]entity.Suggestion, error) {
+	const methodName = "SuggestSongs"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH candidates AS (
+			SELECT s.song_id
+			FROM Artists a
+			JOIN Songs s ON s.artist_id = a.artist_id
+			WHERE a.group_name % $1
+			UNION
+			SELECT song_id
+			FROM Songs
+			WHERE song_name % $2
+		)
+		SELECT s.song_id, a.group_name, s.song_name,
+		       (similarity(a.group_name, $1) + similarity(s.song_name, $2)) / 2 AS score
+		FROM candidates c
+		JOIN Songs s ON s.song_id = c.song_id
+		JOIN Artists a ON s.artist_id = a.artist_id
+		ORDER BY score DESC, s.song_id
+		LIMIT $3`
+
+	rows, err := s.conn(ctx).Query(ctx, query, group, songName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+	defer rows.Close()
+
+	suggestions := make([]entity.Suggestion, 0, limit)
+
+	for rows.Next() {
+		var sg entity.Suggestion
+		if err := rows.Scan(&sg.SongID, &sg.Group, &sg.Song, &sg.Score); err != nil {
+			return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+		}
+		suggestions = append(suggestions, sg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", methodName, translateError(err))
+	}
+
+	return suggestions, nil
+}
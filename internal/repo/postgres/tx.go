@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is the part of *pgxpool.Pool and pgx.Tx the repository relies on.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// txKey carries the transaction of WithTx in a context. Passing it through
+// the context keeps the service free of database types.
+type txKey struct{}
+
+// WithTx runs fn in a transaction. Repository calls made with the context
+// handed to fn join that transaction; an error from fn rolls it back. A
+// WithTx nested in another one joins the outer transaction.
+func (s *Repository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const methodName = "WithTx"
+
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: ошибка начала транзакции: %w", methodName, translateError(err))
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		// Откат идет с контекстом без отмены: истекший ctx не должен
+		// оставить соединение с незавершенной транзакцией.
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			log.Printf("%s: ошибка отката транзакции: %v", methodName, rbErr)
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: ошибка фиксации транзакции: %w", methodName, translateError(err))
+	}
+
+	return nil
+}
+
+// conn returns the transaction carried by ctx, or the pool outside WithTx.
+func (s *Repository) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return s.pool
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+type ArtistRepository interface {
+	EnsureArtist(ctx context.Context, group string) (*entity.Artist, error)
+	CreateArtist(ctx context.Context, input *entity.ArtistInput) (*entity.Artist, error)
+	GetArtistByID(ctx context.Context, id int) (*entity.Artist, error)
+	GetAllArtists(ctx context.Context, pagination entity.Pagination) ([]entity.Artist, int, error)
+	UpdateArtist(ctx context.Context, id int, input *entity.ArtistInput) (*entity.Artist, error)
+	DeleteArtist(ctx context.Context, id int) error
+}
+
+func (s *Service) CreateArtist(ctx context.Context, input *entity.ArtistInput) (*entity.Artist, error) {
+	artist, err := s.repo.CreateArtist(ctx, input)
+
+	return artist, domainError(err)
+}
+
+func (s *Service) GetArtistByID(ctx context.Context, id int) (*entity.Artist, error) {
+	artist, err := s.repo.GetArtistByID(ctx, id)
+
+	return artist, domainError(err)
+}
+
+func (s *Service) GetAllArtists(ctx context.Context, pagination entity.Pagination) ([]entity.Artist, int, error) {
+	artists, total, err := s.repo.GetAllArtists(ctx, pagination)
+
+	return artists, total, domainError(err)
+}
+
+func (s *Service) UpdateArtist(ctx context.Context, id int, input *entity.ArtistInput) (*entity.Artist, error) {
+	artist, err := s.repo.UpdateArtist(ctx, id, input)
+
+	return artist, domainError(err)
+}
+
+// DeleteArtist fails with ErrConflict while songs still reference the artist.
+func (s *Service) DeleteArtist(ctx context.Context, id int) error {
+	return domainError(s.repo.DeleteArtist(ctx, id))
+}
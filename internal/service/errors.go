@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DobryySoul/test-task/internal/client/musicinfo"
+	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/DobryySoul/test-task/internal/repo/postgres"
+)
+
+// Domain errors returned by the service. Handlers map them to HTTP statuses;
+// the wrapped cause stays available through errors.Is/As.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrValidation         = errors.New("validation failed")
+	ErrUpstream           = errors.New("upstream service failure")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrTimeout            = errors.New("timeout")
+)
+
+// ClientError is a domain error with a message written for API clients.
+// Other errors are answered with the text of their domain kind only, as
+// their chain may name repository methods or constraints.
+type ClientError struct {
+	Kind    error
+	Message string
+}
+
+func (e *ClientError) Error() string { return e.Kind.Error() + ": " + e.Message }
+
+func (e *ClientError) Unwrap() error { return e.Kind }
+
+// SuggestionsError is a not found error that carries the closest candidates
+// for the requested song. It unwraps to ErrNotFound.
+type SuggestionsError struct {
+	Suggestions []entity.Suggestion
+	err         error
+}
+
+func (e *SuggestionsError) Error() string { return e.err.Error() }
+
+func (e *SuggestionsError) Unwrap() error { return e.err }
+
+// domainError translates repository and music info errors into the domain
+// set. nil and already translated errors pass through unchanged.
+func domainError(err error) error {
+	var kind error
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrConflict), errors.Is(err, ErrValidation),
+		errors.Is(err, ErrUpstream), errors.Is(err, ErrPreconditionFailed), errors.Is(err, ErrTimeout):
+		return err
+	case errors.Is(err, postgres.ErrInvalidCursor):
+		return &ClientError{Kind: ErrValidation, Message: "cursor is malformed or was issued for a different sort"}
+	case errors.Is(err, postgres.ErrNotFound), errors.Is(err, musicinfo.ErrSongNotFound):
+		kind = ErrNotFound
+	case errors.Is(err, postgres.ErrConflict):
+		kind = ErrConflict
+	case errors.Is(err, postgres.ErrInvalidReference), errors.Is(err, postgres.ErrInvalidInput):
+		kind = ErrValidation
+	case errors.Is(err, postgres.ErrVersionMismatch):
+		kind = ErrPreconditionFailed
+	case errors.Is(err, musicinfo.ErrUnavailable):
+		kind = ErrUpstream
+	case errors.Is(err, postgres.ErrTimeout):
+		kind = ErrTimeout
+	default:
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", kind, err)
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	"github.com/DobryySoul/test-task/internal/entity"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, query string, pagination entity.Pagination) ([]entity.SearchResult, int, error)
+}
+
+// Search finds songs by name, group and lyrics, best matches first.
+func (s *Service) Search(ctx context.Context, query string, pagination entity.Pagination) ([]entity.SearchResult, int, error) {
+	results, total, err := s.repo.Search(ctx, query, pagination)
+
+	return results, total, domainError(err)
+}
@@ -1,63 +1,240 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/DobryySoul/test-task/internal/client/musicinfo"
 	"github.com/DobryySoul/test-task/internal/entity"
+	"github.com/DobryySoul/test-task/internal/repo/postgres"
 )
 
 type Repository interface {
-	CreateSong(song *entity.CreateSongInput) error
-	GetByGroupAndSongName(group, songName string) (*entity.Song, error)
-	// UpdateSong(song *entity.Song, ID int) error
-	UpdateFieldSong(updateField *entity.UpdateSongInput, song *entity.Song) error
-	Delete(id int) error
-	GetByID(id int) (*entity.Song, error)
-	GetAllSongs(filter entity.SongFilter, pagination entity.Pagination) ([]entity.Song, int, error)
+	ArtistRepository
+	SearchRepository
+
+	// WithTx runs fn in a transaction that repository calls made with
+	// fn's context join.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	CreateSong(ctx context.Context, song *entity.Song) error
+	GetByGroupAndSongName(ctx context.Context, group, songName string) (*entity.Song, error)
+	GetByGroupAndSongNameForUpdate(ctx context.Context, group, songName string) (*entity.Song, error)
+	SuggestSongs(ctx context.Context, group, songName string, limit int) ([]entity.Suggestion, error)
+	UpdateSong(ctx context.Context, id int, input *entity.SongInput, versions []int) (*entity.Song, error)
+	UpdateFieldSong(ctx context.Context, id int, patch *entity.UpdateSongInput, versions []int) (*entity.Song, error)
+	Delete(ctx context.Context, id int, versions []int) error
+	GetByID(ctx context.Context, id int) (*entity.Song, error)
+	GetAllSongs(ctx context.Context, filter entity.SongFilter, order []entity.SortField, page entity.SongPage) (*entity.SongList, error)
+}
+
+// MusicInfo is the external API that knows release dates, texts and links.
+type MusicInfo interface {
+	GetSongDetail(ctx context.Context, group, song string) (*entity.GetSongResponse, error)
 }
 
 type Service struct {
-	repo Repository
+	repo      Repository
+	musicInfo MusicInfo
 }
 
-func NewSongService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewSongService(repo Repository, musicInfo MusicInfo) *Service {
+	return &Service{repo: repo, musicInfo: musicInfo}
 }
 
-func (s *Service) CreateSong(song *entity.CreateSongInput) error {
-	return s.repo.CreateSong(song)
+// CreateSong enriches the song with details from the music info API and
+// stores it, creating the artist when the group is not known yet. The
+// artist and the song are created atomically; the API is called before the
+// transaction starts so it is not held open during the request.
+func (s *Service) CreateSong(ctx context.Context, input *entity.CreateSongInput) (*entity.Song, error) {
+	detail, err := s.musicInfo.GetSongDetail(ctx, input.Group, input.SongName)
+	if err != nil {
+		if errors.Is(err, musicinfo.ErrSongNotFound) {
+			return nil, domainError(err)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+
+	releaseDate, err := entity.ParseDate(detail.ReleaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+
+	song := &entity.Song{
+		SongName:    input.SongName,
+		ReleaseDate: releaseDate,
+		SongText:    &detail.Text,
+		Link:        &detail.Link,
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		artist, err := s.repo.EnsureArtist(ctx, input.Group)
+		if err != nil {
+			return err
+		}
+
+		song.ArtistID = artist.ArtistID
+		song.Group = artist.GroupName
+
+		return s.repo.CreateSong(ctx, song)
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return song, nil
 }
 
-func (s *Service) GetByGroupAndSongName(group, songName string) (*entity.Song, error) {
-	return s.repo.GetByGroupAndSongName(group, songName)
+// UpdateFieldSongByName patches the song found by group and song name. The
+// row stays locked between the lookup and the write, so a concurrent rename
+// cannot make the patch hit another song.
+func (s *Service) UpdateFieldSongByName(ctx context.Context, group, songName string, patch *entity.UpdateSongInput, versions []int) (*entity.Song, error) {
+	var song *entity.Song
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByGroupAndSongNameForUpdate(ctx, group, songName)
+		if err != nil {
+			return err
+		}
+
+		song, err = s.repo.UpdateFieldSong(ctx, current.SongID, patch, versions)
+
+		return err
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return song, nil
 }
 
-// func (s *Service) UpdateSong(song *entity.Song, ID int) error {
-// 	return s.repo.UpdateSong(song, ID)
-// }
+// DeleteByName deletes the song found by group and song name under the
+// same row lock as UpdateFieldSongByName and returns the deleted song.
+func (s *Service) DeleteByName(ctx context.Context, group, songName string, versions []int) (*entity.Song, error) {
+	var song *entity.Song
 
-func (s *Service) UpdateFieldSong(updateField *entity.UpdateSongInput, song *entity.Song) error {
-	return s.repo.UpdateFieldSong(updateField, song)
+	err := s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		song, err = s.repo.GetByGroupAndSongNameForUpdate(ctx, group, songName)
+		if err != nil {
+			return err
+		}
+
+		return s.repo.Delete(ctx, song.SongID, versions)
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return song, nil
 }
 
-func (s *Service) Delete(id int) error {
-	return s.repo.Delete(id)
+// maxSuggestions bounds the "did you mean" list of FindSong.
+const maxSuggestions = 5
+
+// FindSong looks the song up by exact group and song name. Without an exact
+// match it falls back to trigram similarity: in fuzzy mode the best
+// candidate is returned, otherwise a SuggestionsError lists the candidates.
+func (s *Service) FindSong(ctx context.Context, group, songName string, fuzzy bool) (*entity.Song, error) {
+	song, err := s.repo.GetByGroupAndSongName(ctx, group, songName)
+	if !errors.Is(err, postgres.ErrNotFound) {
+		return song, domainError(err)
+	}
+
+	suggestions, sErr := s.repo.SuggestSongs(ctx, group, songName, maxSuggestions)
+	if sErr != nil {
+		return nil, domainError(sErr)
+	}
+
+	if fuzzy && len(suggestions) > 0 {
+		song, err := s.repo.GetByID(ctx, suggestions[0].SongID)
+
+		return song, domainError(err)
+	}
+
+	return nil, &SuggestionsError{Suggestions: suggestions, err: domainError(err)}
 }
 
-func (s *Service) GetSongByID(id int) (*entity.Song, error) {
-	return s.repo.GetByID(id)
+// UpdateSong, UpdateFieldSong and Delete only apply when the stored version
+// is one of versions; nil skips the check.
+func (s *Service) UpdateSong(ctx context.Context, id int, input *entity.SongInput, versions []int) (*entity.Song, error) {
+	song, err := s.repo.UpdateSong(ctx, id, input, versions)
+
+	return song, domainError(err)
+}
+
+func (s *Service) UpdateFieldSong(ctx context.Context, id int, patch *entity.UpdateSongInput, versions []int) (*entity.Song, error) {
+	song, err := s.repo.UpdateFieldSong(ctx, id, patch, versions)
+
+	return song, domainError(err)
+}
+
+func (s *Service) Delete(ctx context.Context, id int, versions []int) error {
+	return domainError(s.repo.Delete(ctx, id, versions))
+}
+
+func (s *Service) GetSongByID(ctx context.Context, id int) (*entity.Song, error) {
+	song, err := s.repo.GetByID(ctx, id)
+
+	return song, domainError(err)
 }
 
-func (s *Service) GetSongText(id int) ([]string, error) {
-	song, err := s.repo.GetByID(id)
+// GetSongText returns the song with its lyrics split into verses.
+func (s *Service) GetSongText(ctx context.Context, id int) (*entity.Song, []string, error) {
+	song, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, nil, domainError(err)
 	}
 
-	verses := strings.Split(song.SongText, "\n")
-	return verses, nil
+	var text string
+	if song.SongText != nil {
+		text = *song.SongText
+	}
+
+	return song, splitVerses(text), nil
 }
 
-func (s *Service) GetAllSongs(filter entity.SongFilter, pagination entity.Pagination) ([]entity.Song, int, error) {
-	return s.repo.GetAllSongs(filter, pagination)
+// splitVerses splits lyrics into verses separated by blank lines. Windows
+// and old Mac line endings are normalised first; lines inside a verse keep
+// their "\n" separators.
+func splitVerses(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	verses := []string{}
+	var verse []string
+
+	flush := func() {
+		if len(verse) > 0 {
+			verses = append(verses, strings.Join(verse, "\n"))
+			verse = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			flush()
+
+			continue
+		}
+
+		verse = append(verse, line)
+	}
+	flush()
+
+	return verses
+}
+
+func (s *Service) GetAllSongs(ctx context.Context, filter entity.SongFilter, order []entity.SortField, page entity.SongPage) (*entity.SongList, error) {
+	list, err := s.repo.GetAllSongs(ctx, filter, order, page)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return list, nil
 }
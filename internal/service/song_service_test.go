@@ -0,0 +1,33 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitVerses(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: []string{}},
+		{name: "blank only", text: "\n \n\t\n", want: []string{}},
+		{name: "single verse", text: "a\nb", want: []string{"a\nb"}},
+		{name: "two verses", text: "a\nb\n\nc\nd", want: []string{"a\nb", "c\nd"}},
+		{name: "windows line endings", text: "a\r\nb\r\n\r\nc", want: []string{"a\nb", "c"}},
+		{name: "old mac line endings", text: "a\rb\r\rc", want: []string{"a\nb", "c"}},
+		{name: "whitespace-only separator", text: "a\n  \t \nb", want: []string{"a", "b"}},
+		{name: "repeated blank lines", text: "\n\na\n\n\n\nb\n\n", want: []string{"a", "b"}},
+		{name: "trailing whitespace trimmed", text: "a  \nb\t\n\nc ", want: []string{"a\nb", "c"}},
+		{name: "leading indentation kept", text: "  a\n  b", want: []string{"  a\n  b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitVerses(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitVerses(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
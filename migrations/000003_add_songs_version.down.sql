@@ -0,0 +1 @@
+ALTER TABLE Songs DROP COLUMN IF EXISTS version;
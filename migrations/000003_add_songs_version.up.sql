@@ -0,0 +1 @@
+ALTER TABLE Songs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP INDEX IF EXISTS songs_link_idx;
+DROP INDEX IF EXISTS songs_release_date_idx;
+DROP INDEX IF EXISTS songs_song_name_idx;
+
+ALTER TABLE Songs DROP CONSTRAINT songs_artist_id_fkey;
+ALTER TABLE Songs ADD CONSTRAINT songs_artist_id_fkey
+    FOREIGN KEY (artist_id) REFERENCES Artists(artist_id);
+
+ALTER TABLE Songs DROP CONSTRAINT IF EXISTS songs_artist_id_song_name_key;
+ALTER TABLE Artists DROP CONSTRAINT IF EXISTS artists_group_name_key;
+
+CREATE SEQUENCE IF NOT EXISTS songs_artist_id_seq OWNED BY Songs.artist_id;
+SELECT setval('songs_artist_id_seq', COALESCE(MAX(artist_id), 0) + 1, false) FROM Songs;
+ALTER TABLE Songs ALTER COLUMN artist_id SET DEFAULT nextval('songs_artist_id_seq');
+
+COMMIT;
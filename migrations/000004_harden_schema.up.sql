@@ -0,0 +1,57 @@
+BEGIN;
+
+-- artist_id ссылается на Artists, собственная последовательность ему не нужна.
+ALTER TABLE Songs ALTER COLUMN artist_id DROP DEFAULT;
+ALTER TABLE Songs ALTER COLUMN artist_id TYPE INTEGER;
+DROP SEQUENCE IF EXISTS songs_artist_id_seq;
+
+-- Дубликаты исполнителей сливаются в запись с наименьшим artist_id.
+UPDATE Songs s
+SET artist_id = d.keep_id
+FROM (
+    SELECT artist_id, MIN(artist_id) OVER (PARTITION BY group_name) AS keep_id
+    FROM Artists
+) d
+WHERE s.artist_id = d.artist_id AND d.artist_id <> d.keep_id;
+
+DELETE FROM Artists a
+USING Artists b
+WHERE a.group_name = b.group_name AND a.artist_id > b.artist_id;
+
+-- Одинаковые песни одного исполнителя не удаляются вместе с текстами:
+-- миграция останавливается и перечисляет их, чтобы оператор сам решил,
+-- какие переименовать или удалить.
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(format('%s / %s: song_id %s', a.group_name, d.song_name, d.song_ids), E'\n'
+                      ORDER BY a.group_name, d.song_name)
+    INTO duplicates
+    FROM (
+        SELECT artist_id, song_name, string_agg(song_id::TEXT, ', ' ORDER BY song_id) AS song_ids
+        FROM Songs
+        GROUP BY artist_id, song_name
+        HAVING COUNT(*) > 1
+    ) d
+    JOIN Artists a ON a.artist_id = d.artist_id;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION E'duplicate songs of one artist, rename or delete them and run the migration again:\n%', duplicates;
+    END IF;
+END
+$$;
+
+ALTER TABLE Artists ADD CONSTRAINT artists_group_name_key UNIQUE (group_name);
+ALTER TABLE Songs ADD CONSTRAINT songs_artist_id_song_name_key UNIQUE (artist_id, song_name);
+
+ALTER TABLE Songs DROP CONSTRAINT songs_artist_id_fkey;
+ALTER TABLE Songs ADD CONSTRAINT songs_artist_id_fkey
+    FOREIGN KEY (artist_id) REFERENCES Artists(artist_id) ON DELETE RESTRICT;
+
+-- Фильтры GetAllSongs; artist_id покрыт уникальным индексом выше.
+CREATE INDEX songs_song_name_idx ON Songs (song_name);
+CREATE INDEX songs_release_date_idx ON Songs (release_date);
+CREATE INDEX songs_link_idx ON Songs (link);
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS artists_search_vector_update ON Artists;
+DROP FUNCTION IF EXISTS artists_search_vector_update();
+DROP TRIGGER IF EXISTS songs_search_vector_update ON Songs;
+DROP FUNCTION IF EXISTS songs_search_vector_update();
+DROP FUNCTION IF EXISTS songs_search_document(TEXT, TEXT, TEXT);
+
+DROP INDEX IF EXISTS songs_search_vector_idx;
+ALTER TABLE Songs DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
@@ -0,0 +1,55 @@
+BEGIN;
+
+ALTER TABLE Songs ADD COLUMN search_vector tsvector;
+
+-- Конфигурация 'simple' не зависит от языка: тексты песен бывают на разных
+-- языках, а стемминг одного из них портит поиск по остальным.
+CREATE FUNCTION songs_search_document(song_name TEXT, group_name TEXT, song_text TEXT)
+RETURNS tsvector
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(song_name, '')), 'A')
+        || setweight(to_tsvector('simple', COALESCE(group_name, '')), 'B')
+        || setweight(to_tsvector('simple', COALESCE(song_text, '')), 'C')
+$$;
+
+CREATE FUNCTION songs_search_vector_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := songs_search_document(
+        NEW.song_name,
+        (SELECT group_name FROM Artists WHERE artist_id = NEW.artist_id),
+        NEW.song_text
+    );
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER songs_search_vector_update
+    BEFORE INSERT OR UPDATE OF song_name, song_text, artist_id ON Songs
+    FOR EACH ROW EXECUTE FUNCTION songs_search_vector_update();
+
+-- Переименование исполнителя меняет документ всех его песен.
+CREATE FUNCTION artists_search_vector_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE Songs
+    SET search_vector = songs_search_document(song_name, NEW.group_name, song_text)
+    WHERE artist_id = NEW.artist_id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER artists_search_vector_update
+    AFTER UPDATE OF group_name ON Artists
+    FOR EACH ROW
+    WHEN (OLD.group_name IS DISTINCT FROM NEW.group_name)
+    EXECUTE FUNCTION artists_search_vector_update();
+
+UPDATE Songs s
+SET search_vector = songs_search_document(s.song_name, a.group_name, s.song_text)
+FROM Artists a
+WHERE a.artist_id = s.artist_id;
+
+CREATE INDEX songs_search_vector_idx ON Songs USING GIN (search_vector);
+
+COMMIT;
@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Нечеткий поиск /info по группе и названию песни.
+CREATE INDEX artists_group_name_trgm_idx ON Artists USING GIN (group_name gin_trgm_ops);
+CREATE INDEX songs_song_name_trgm_idx ON Songs USING GIN (song_name gin_trgm_ops);
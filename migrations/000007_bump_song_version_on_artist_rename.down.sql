@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION artists_search_vector_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE Songs
+    SET search_vector = songs_search_document(song_name, NEW.group_name, song_text)
+    WHERE artist_id = NEW.artist_id;
+    RETURN NULL;
+END
+$$;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Название группы входит в ответ о песне, поэтому переименование исполнителя
+-- меняет не только поисковый документ, но и версию (ETag) всех его песен.
+CREATE OR REPLACE FUNCTION artists_search_vector_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    UPDATE Songs
+    SET search_vector = songs_search_document(song_name, NEW.group_name, song_text),
+        version = version + 1
+    WHERE artist_id = NEW.artist_id;
+    RETURN NULL;
+END
+$$;
+
+COMMIT;